@@ -0,0 +1,114 @@
+package ask
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jhandguy/jira-prompt/cmd/client"
+	"github.com/jhandguy/jira-prompt/internal/jira"
+	"github.com/jhandguy/jira-prompt/internal/llm"
+	"github.com/jhandguy/jira-prompt/internal/vector"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "ask QUESTION",
+	Short: "Ask Ollama a question about the indexed Jira issues",
+	Long: `Ask Ollama a question about the Jira issues indexed with jp index, retrieving the --top-k issues most relevant
+to the question from the index and prompting the model with those only.`,
+	Args:          cobra.ExactArgs(1),
+	RunE:          ask,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+var (
+	system       string
+	topK         int
+	ollamaStream bool
+)
+
+func init() {
+	client.IndexFlags(Cmd)
+	Cmd.Flags().IntVarP(&topK, "top-k", "k", 5, "number of jira issues most relevant to the question given to the model")
+	Cmd.Flags().BoolVarP(&ollamaStream, "ollama-stream", "s", true, "enable ollama streaming")
+	Cmd.Flags().StringVar(&system, "system", "You are an assistant answering questions about Jira issues, citing their keys. The following JSON representation holds the Jira issues most relevant to the question:", "system prompt, followed by the relevant Jira issues")
+}
+
+func ask(cmd *cobra.Command, args []string) error {
+	question := args[0]
+	if topK < 1 {
+		return fmt.Errorf("top-k must be at least 1, got %d", topK)
+	}
+
+	embedModel, err := cmd.Flags().GetString("embed-model")
+	if err != nil {
+		return err
+	}
+
+	path, err := client.IndexPath(cmd)
+	if err != nil {
+		return err
+	}
+
+	projection, err := client.Projection(cmd)
+	if err != nil {
+		return err
+	}
+
+	ollamaModel, err := cmd.InheritedFlags().GetString("ollama-model")
+	if err != nil {
+		return err
+	}
+
+	llmClient, err := client.LLM(cmd)
+	if err != nil {
+		return err
+	}
+
+	idx, err := vector.Load(path)
+	if err != nil {
+		return err
+	}
+
+	if len(idx.Entries) == 0 {
+		return errors.New("the index is empty, index the jira issues with jp index first")
+	}
+
+	// The question is only comparable to the issues if embedded with the same model
+	if idx.Model != embedModel {
+		return fmt.Errorf("the index was embedded with %s, not %s, set it with --embed-model or re-index the jira issues with jp index", idx.Model, embedModel)
+	}
+
+	vectors, err := llmClient.Embed(cmd.Context(), embedModel, []string{question})
+	if err != nil {
+		return err
+	}
+
+	matches := idx.Search(vectors[0], topK)
+	issues := make([]jira.Issue, len(matches))
+	for i, match := range matches {
+		zap.S().Debugf("%s: %.3f", match.Issue.Key, match.Score)
+		issues[i] = match.Issue
+	}
+
+	jiraResponse, err := projection.Encode(&jira.SearchResponse{Issues: issues})
+	if err != nil {
+		return err
+	}
+
+	message, err := llmClient.Chat(cmd.Context(), ollamaModel, []llm.Message{
+		{Role: llm.RoleSystem, Content: fmt.Sprintf("%s\n%s", system, jiraResponse)},
+		{Role: llm.RoleUser, Content: question},
+	}, ollamaStream)
+	if err != nil {
+		return err
+	}
+
+	// A streamed message has already been printed
+	if !ollamaStream {
+		fmt.Print(message.Content)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package auth
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jhandguy/jira-prompt/cmd/client"
+	"github.com/jhandguy/jira-prompt/internal/credential"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"golang.org/x/term"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage the Jira credentials",
+	Long: `Manage the Jira credentials stored per Jira url in the credential store, which are used when --jira-token is not set.
+
+The credential store is set with --credential-store:
+  file                 a JSON file only readable by the user (default)
+  encrypted            a file encrypted with the passphrase of JP_CREDENTIAL_PASSPHRASE
+  helper:<command>     a git credential helper, e.g. helper:osxkeychain, helper:libsecret or helper:manager for the OS keyring`,
+}
+
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in to Jira and store the credential",
+	Long: `Log in to Jira with the token read from the terminal without echoing it, or from the standard input if piped,
+e.g. echo "$TOKEN" | jp auth login --jira-url https://example.atlassian.net --jira-email user@example.com`,
+	RunE:          login,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+var logoutCmd = &cobra.Command{
+	Use:           "logout",
+	Short:         "Delete the stored credential",
+	RunE:          logout,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+var statusCmd = &cobra.Command{
+	Use:           "status",
+	Short:         "Show the Jira user the credential logs in as",
+	RunE:          status,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	Cmd.AddCommand(loginCmd)
+	Cmd.AddCommand(logoutCmd)
+	Cmd.AddCommand(statusCmd)
+}
+
+func login(cmd *cobra.Command, _ []string) error {
+	jiraURL, err := client.JiraURL(cmd)
+	if err != nil {
+		return err
+	}
+
+	mode, err := cmd.Flags().GetString("jira-auth")
+	if err != nil {
+		return err
+	}
+	if mode == "oauth2" {
+		return errors.New("oauth2 access tokens are granted with the --jira-oauth-* flags and are not stored")
+	}
+
+	jiraEmail, err := cmd.Flags().GetString("jira-email")
+	if err != nil {
+		return err
+	}
+
+	jiraToken, err := cmd.Flags().GetString("jira-token")
+	if err != nil {
+		return err
+	}
+
+	if jiraToken == "" {
+		reader := bufio.NewReader(os.Stdin)
+		interactive := term.IsTerminal(int(os.Stdin.Fd()))
+		if mode == "basic" && jiraEmail == "" && interactive {
+			fmt.Print("Email (empty for a base64 encoded email:token): ")
+			if jiraEmail, err = readLine(reader); err != nil {
+				return err
+			}
+		}
+
+		if jiraToken, err = readToken(reader, interactive); err != nil {
+			return err
+		}
+	}
+	if jiraToken == "" {
+		return errors.New("jira token is required to log in")
+	}
+
+	// The credential is verified before being stored
+	if err = cmd.Flags().Set("jira-email", jiraEmail); err != nil {
+		return err
+	}
+	if err = cmd.Flags().Set("jira-token", jiraToken); err != nil {
+		return err
+	}
+
+	jiraClient, err := client.Jira(cmd)
+	if err != nil {
+		return err
+	}
+
+	user, err := jiraClient.Myself(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to log in to %s: %w", jiraURL, err)
+	}
+
+	store, err := client.Credentials(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err = store.Set(jiraURL, credential.Credential{Email: jiraEmail, Token: jiraToken}); err != nil {
+		return err
+	}
+
+	zap.S().Infof("✅ Logged in to %s as %s!", jiraURL, user.DisplayName)
+	return nil
+}
+
+// readToken reads the token from the terminal without echoing it, or else from the first line of the reader.
+func readToken(reader *bufio.Reader, interactive bool) (string, error) {
+	if !interactive {
+		return readLine(reader)
+	}
+
+	fmt.Print("Token: ")
+	token, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Println()
+	if err != nil {
+		return "", fmt.Errorf("failed to read token: %w", err)
+	}
+	return strings.TrimSpace(string(token)), nil
+}
+
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read standard input: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func logout(cmd *cobra.Command, _ []string) error {
+	jiraURL, err := client.JiraURL(cmd)
+	if err != nil {
+		return err
+	}
+
+	store, err := client.Credentials(cmd)
+	if err != nil {
+		return err
+	}
+
+	if err = store.Delete(jiraURL); err != nil {
+		return err
+	}
+
+	zap.S().Infof("👋 Logged out of %s!", jiraURL)
+	return nil
+}
+
+func status(cmd *cobra.Command, _ []string) error {
+	jiraURL, err := client.JiraURL(cmd)
+	if err != nil {
+		return err
+	}
+
+	source, err := credentialSource(cmd, jiraURL)
+	if err != nil {
+		return err
+	}
+	if source == "" {
+		return fmt.Errorf("not logged in to %s, log in with jp auth login", jiraURL)
+	}
+
+	jiraClient, err := client.Jira(cmd)
+	if err != nil {
+		return err
+	}
+
+	user, err := jiraClient.Myself(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Logged in to %s as %s", jiraURL, user.DisplayName)
+	if user.EmailAddress != "" {
+		fmt.Printf(" (%s)", user.EmailAddress)
+	}
+	fmt.Printf(" with %s\n", source)
+	return nil
+}
+
+// credentialSource returns where the credential comes from, empty if there is none.
+func credentialSource(cmd *cobra.Command, jiraURL string) (string, error) {
+	mode, err := cmd.Flags().GetString("jira-auth")
+	if err != nil {
+		return "", err
+	}
+	if mode == "oauth2" {
+		return "OAuth 2.0", nil
+	}
+
+	jiraToken, err := cmd.Flags().GetString("jira-token")
+	if err != nil {
+		return "", err
+	}
+	if jiraToken != "" {
+		return "the jira token flag", nil
+	}
+
+	spec, err := cmd.Flags().GetString("credential-store")
+	if err != nil {
+		return "", err
+	}
+
+	store, err := client.Credentials(cmd)
+	if err != nil {
+		return "", err
+	}
+
+	stored, err := store.Get(jiraURL)
+	if err != nil || stored == nil {
+		return "", err
+	}
+	return fmt.Sprintf("the %s credential store", spec), nil
+}
@@ -0,0 +1,359 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jhandguy/jira-prompt/cmd/client"
+	"github.com/jhandguy/jira-prompt/internal/config"
+	"github.com/jhandguy/jira-prompt/internal/jira"
+	"github.com/jhandguy/jira-prompt/internal/llm"
+	"github.com/peterh/liner"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "chat",
+	Short: "Chat with Ollama about Jira data",
+	Long: `Chat with Ollama about the Jira issues fetched once, asking successive questions with the following commands:
+  /refresh        fetch the Jira issues again
+  /jql <query>    fetch the Jira issues matching another JQL query
+  /model <name>   chat with another ollama AI model
+  /save [file]    save the conversation as Markdown
+  /clear          clear the conversation
+  /help           show the commands
+  /exit           exit the chat
+
+Questions are edited as in a shell, with the history of the previous chats (in $XDG_CONFIG_HOME/jp/chat_history)
+browsed with the arrow keys and the commands completed with tab.
+Interrupting an answer or a command with Ctrl-C stops it only, while Ctrl-C or Ctrl-D at the prompt exits the chat.`,
+	RunE:          chat,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+var system, textFormat string
+
+func init() {
+	client.SearchFlags(Cmd)
+	Cmd.Flags().StringVar(&textFormat, "text-format", string(jira.TextMarkdown), "format of the jira rich text fields, such as the description: raw, markdown or plain")
+	Cmd.Flags().StringVar(&system, "system", "You are an assistant answering questions about the following JSON representation of a Jira board:", "system prompt, followed by the Jira data")
+}
+
+type session struct {
+	jiraClient     *jira.Jira
+	llmClient      llm.LLM
+	jiraRequest    jira.SearchRequest
+	jiraDetails    jira.Details
+	jiraProjection jira.Projection
+	jiraTextFormat jira.TextFormat
+	ollamaModel    string
+	messages       []llm.Message
+}
+
+func chat(cmd *cobra.Command, _ []string) error {
+	jiraClient, err := client.Jira(cmd)
+	if err != nil {
+		return err
+	}
+
+	jiraRequest, err := client.SearchRequest(cmd)
+	if err != nil {
+		return err
+	}
+
+	jiraDetails, err := client.Details(cmd)
+	if err != nil {
+		return err
+	}
+
+	jiraProjection, err := client.Projection(cmd)
+	if err != nil {
+		return err
+	}
+
+	jiraTextFormat, err := jira.ParseTextFormat(textFormat)
+	if err != nil {
+		return err
+	}
+
+	ollamaModel, err := cmd.InheritedFlags().GetString("ollama-model")
+	if err != nil {
+		return err
+	}
+
+	llmClient, err := client.LLM(cmd)
+	if err != nil {
+		return err
+	}
+
+	s := &session{
+		jiraClient:     jiraClient,
+		llmClient:      llmClient,
+		jiraRequest:    jiraRequest,
+		jiraDetails:    jiraDetails,
+		jiraProjection: jiraProjection,
+		jiraTextFormat: jiraTextFormat,
+		ollamaModel:    ollamaModel,
+	}
+
+	if err = s.refresh(cmd.Context()); err != nil {
+		return err
+	}
+
+	// The chat outlives the interrupts of its answers and commands, which only cancel their own context
+	ctx, stop := signal.NotifyContext(context.WithoutCancel(cmd.Context()), syscall.SIGTERM)
+	defer stop()
+
+	line := liner.NewLiner()
+	defer line.Close()
+	line.SetCtrlCAborts(true)
+	line.SetCompleter(complete)
+
+	history, err := historyPath()
+	if err != nil {
+		return err
+	}
+	loadHistory(line, history)
+	defer saveHistory(line, history)
+
+	fmt.Println("Ask a question about the Jira issues, or type /help for the commands.")
+	for {
+		input, err := readLine(ctx, line)
+		if errors.Is(err, io.EOF) || errors.Is(err, liner.ErrPromptAborted) {
+			fmt.Println()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+		line.AppendHistory(input)
+
+		if input == "/exit" || input == "/quit" {
+			return nil
+		}
+
+		inputCtx, stopInput := signal.NotifyContext(ctx, os.Interrupt)
+		err = s.run(inputCtx, cmd, input)
+		interrupted := inputCtx.Err() != nil && ctx.Err() == nil
+		stopInput()
+
+		switch {
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case interrupted:
+			zap.S().Warnf("🛑 Interrupted!")
+		case err != nil:
+			zap.S().Errorf("❌ %v", err)
+		}
+	}
+}
+
+// run answers the question, or runs the command, of the input.
+func (s *session) run(ctx context.Context, cmd *cobra.Command, input string) error {
+	if !strings.HasPrefix(input, "/") {
+		return s.ask(ctx, input)
+	}
+
+	command, arg, _ := strings.Cut(input, " ")
+	arg = strings.TrimSpace(arg)
+	switch command {
+	case "/refresh":
+		return s.refresh(ctx)
+	case "/jql":
+		return s.jql(ctx, arg)
+	case "/model":
+		s.model(arg)
+	case "/save":
+		return s.save(arg)
+	case "/clear":
+		s.messages = s.messages[:1]
+		zap.S().Infof("🧹 Conversation cleared!")
+	case "/help":
+		fmt.Println(cmd.Long)
+	default:
+		return fmt.Errorf("unknown command %s, type /help for the commands", command)
+	}
+	return nil
+}
+
+// commands are the commands of the chat, completed with tab.
+var commands = []string{"/refresh", "/jql ", "/model ", "/save ", "/clear", "/help", "/exit"}
+
+func complete(line string) []string {
+	var completions []string
+	for _, command := range commands {
+		if strings.HasPrefix(command, line) {
+			completions = append(completions, command)
+		}
+	}
+	return completions
+}
+
+// readLine reads a line with line editing and history in the background, so that waiting for it can be interrupted.
+func readLine(ctx context.Context, line *liner.State) (string, error) {
+	type result struct {
+		input string
+		err   error
+	}
+
+	results := make(chan result, 1)
+	go func() {
+		input, err := line.Prompt("> ")
+		results <- result{input, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case r := <-results:
+		return r.input, r.err
+	}
+}
+
+// historyPath returns the path of the history of the questions and commands of the chat.
+func historyPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "chat_history"), nil
+}
+
+// loadHistory loads the history of the previous chats, if any.
+func loadHistory(line *liner.State, path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	if _, err = line.ReadHistory(f); err != nil {
+		zap.S().Debugf("failed to read chat history: %v", err)
+	}
+}
+
+// saveHistory saves the history of the chat, for the next ones.
+func saveHistory(line *liner.State, path string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		zap.S().Debugf("failed to create chat history directory: %v", err)
+		return
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		zap.S().Debugf("failed to write chat history: %v", err)
+		return
+	}
+	defer f.Close()
+
+	if _, err = line.WriteHistory(f); err != nil {
+		zap.S().Debugf("failed to write chat history: %v", err)
+	}
+}
+
+// refresh fetches the Jira issues and replaces the Jira data of the system message, keeping the conversation.
+func (s *session) refresh(ctx context.Context) error {
+	res, err := s.jiraClient.SearchIssues(ctx, s.jiraRequest)
+	if err != nil {
+		return err
+	}
+
+	if err = s.jiraClient.FetchDetails(ctx, res.Issues, s.jiraDetails); err != nil {
+		return err
+	}
+	res.Issues = jira.ConvertText(res.Issues, s.jiraTextFormat)
+
+	jiraResponse, err := s.jiraProjection.Encode(res)
+	if err != nil {
+		return err
+	}
+
+	message := llm.Message{
+		Role:    llm.RoleSystem,
+		Content: fmt.Sprintf("%s\n%s", system, jiraResponse),
+	}
+	if len(s.messages) == 0 {
+		s.messages = []llm.Message{message}
+	} else {
+		s.messages[0] = message
+	}
+	return nil
+}
+
+// jql replaces the JQL query of the Jira request and fetches the matching issues.
+func (s *session) jql(ctx context.Context, query string) error {
+	if query == "" {
+		return errors.New("a JQL query is required, e.g. /jql project = PROJ")
+	}
+
+	// The previous request is kept if the new one fails
+	previous := s.jiraRequest
+	s.jiraRequest.JQL = query
+	if err := s.refresh(ctx); err != nil {
+		s.jiraRequest = previous
+		return err
+	}
+	return nil
+}
+
+// model switches the ollama AI model, or prints the current one.
+func (s *session) model(name string) {
+	if name == "" {
+		fmt.Println(s.ollamaModel)
+		return
+	}
+
+	s.ollamaModel = name
+	zap.S().Infof("🤖 Switched to %s model!", name)
+}
+
+// ask streams the answer to the question, keeping both in the conversation unless it fails or is interrupted.
+func (s *session) ask(ctx context.Context, question string) error {
+	messages := append(slices.Clip(s.messages), llm.Message{Role: llm.RoleUser, Content: question})
+
+	answer, err := s.llmClient.Chat(ctx, s.ollamaModel, messages, true)
+	fmt.Println()
+	if err != nil {
+		return err
+	}
+
+	s.messages = append(messages, answer)
+	return nil
+}
+
+// save writes the conversation, without the Jira data, to a Markdown file.
+func (s *session) save(path string) error {
+	if path == "" {
+		path = fmt.Sprintf("jp-chat-%s.md", time.Now().Format("20060102-150405"))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Chat about `%s` with %s\n", s.jiraRequest.JQL, s.ollamaModel)
+
+	for _, message := range s.messages[1:] {
+		fmt.Fprintf(&sb, "\n## %s\n\n%s\n", message.Role, strings.TrimSpace(message.Content))
+	}
+
+	if err := os.WriteFile(path, []byte(sb.String()), 0o644); err != nil {
+		return fmt.Errorf("failed to save conversation: %w", err)
+	}
+
+	zap.S().Infof("💾 Conversation saved to %s!", path)
+	return nil
+}
@@ -0,0 +1,626 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jhandguy/jira-prompt/internal/config"
+	"github.com/jhandguy/jira-prompt/internal/credential"
+	"github.com/jhandguy/jira-prompt/internal/jira"
+	"github.com/jhandguy/jira-prompt/internal/llm"
+	"github.com/jhandguy/jira-prompt/internal/ollama"
+	"github.com/jhandguy/jira-prompt/internal/openai"
+	"github.com/jhandguy/jira-prompt/internal/schema"
+	"github.com/jhandguy/jira-prompt/internal/summarize"
+	"github.com/jhandguy/jira-prompt/internal/vector"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+	"golang.org/x/term"
+)
+
+// JiraURL returns the required jira url of the command.
+func JiraURL(cmd *cobra.Command) (string, error) {
+	jiraURL, err := cmd.Flags().GetString("jira-url")
+	if err != nil {
+		return "", err
+	}
+	if jiraURL == "" {
+		return "", errors.New("jira url is required, set it with --jira-url, JP_JIRA_URL or a config file profile")
+	}
+	return jiraURL, nil
+}
+
+// Credentials opens the credential store of the command.
+func Credentials(cmd *cobra.Command) (credential.Store, error) {
+	spec, err := cmd.Flags().GetString("credential-store")
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	return credential.Open(spec, dir, os.Getenv(config.EnvVar("credential-passphrase")))
+}
+
+// Jira creates a Jira client from the jira flags of the command.
+func Jira(cmd *cobra.Command) (*jira.Jira, error) {
+	jiraURL, err := JiraURL(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	jiraAuth, err := auth(cmd, jiraURL)
+	if err != nil {
+		return nil, err
+	}
+
+	jiraTimeout, err := cmd.Flags().GetDuration("jira-timeout")
+	if err != nil {
+		return nil, err
+	}
+
+	jiraRetries, err := cmd.Flags().GetInt("jira-retries")
+	if err != nil {
+		return nil, err
+	}
+
+	jiraRetryWait, err := cmd.Flags().GetDuration("jira-retry-wait")
+	if err != nil {
+		return nil, err
+	}
+
+	jiraRetryMaxWait, err := cmd.Flags().GetDuration("jira-retry-max-wait")
+	if err != nil {
+		return nil, err
+	}
+
+	jiraAPIVersion, err := cmd.Flags().GetInt("jira-api-version")
+	if err != nil {
+		return nil, err
+	}
+
+	return jira.
+		New(jiraURL, "").
+		WithAuth(jiraAuth).
+		WithTimeout(jiraTimeout).
+		WithRetry(jiraRetries, jiraRetryWait, jiraRetryMaxWait).
+		WithAPIVersion(jiraAPIVersion)
+}
+
+// auth creates the Jira auth from the --jira-auth mode and its flags,
+// with the credential stored for the jira url unless --jira-token is set.
+func auth(cmd *cobra.Command, jiraURL string) (jira.Auth, error) {
+	mode, err := cmd.Flags().GetString("jira-auth")
+	if err != nil {
+		return nil, err
+	}
+
+	jiraToken, err := cmd.Flags().GetString("jira-token")
+	if err != nil {
+		return nil, err
+	}
+
+	jiraEmail, err := cmd.Flags().GetString("jira-email")
+	if err != nil {
+		return nil, err
+	}
+
+	if jiraToken == "" && mode != "oauth2" {
+		store, err := Credentials(cmd)
+		if err != nil {
+			return nil, err
+		}
+
+		stored, err := store.Get(jiraURL)
+		if err != nil {
+			return nil, err
+		}
+
+		if stored != nil {
+			zap.S().Debugf("🔑 Using the stored credential of %s", jiraURL)
+			jiraToken = stored.Token
+			if jiraEmail == "" {
+				jiraEmail = stored.Email
+			}
+		}
+	}
+
+	switch mode {
+	case "basic":
+		return jira.BasicAuth{Email: jiraEmail, Token: jiraToken}, nil
+	case "bearer", "pat":
+		if jiraToken == "" {
+			return nil, fmt.Errorf("jira token is required with %s auth, set it with jp auth login, --jira-token or JP_JIRA_TOKEN", mode)
+		}
+		return jira.BearerAuth{Token: jiraToken}, nil
+	case "oauth2":
+		tokenURL, err := cmd.Flags().GetString("jira-oauth-token-url")
+		if err != nil {
+			return nil, err
+		}
+
+		clientID, err := cmd.Flags().GetString("jira-oauth-client-id")
+		if err != nil {
+			return nil, err
+		}
+
+		clientSecret, err := cmd.Flags().GetString("jira-oauth-client-secret")
+		if err != nil {
+			return nil, err
+		}
+
+		refreshToken, err := cmd.Flags().GetString("jira-oauth-refresh-token")
+		if err != nil {
+			return nil, err
+		}
+
+		if clientID == "" || clientSecret == "" {
+			return nil, errors.New("jira oauth client id and secret are required with oauth2 auth, set them with --jira-oauth-client-id and --jira-oauth-client-secret")
+		}
+		return jira.NewOAuth2(tokenURL, clientID, clientSecret, refreshToken), nil
+	default:
+		return nil, fmt.Errorf("unknown jira auth %q, expected one of basic, bearer, pat or oauth2", mode)
+	}
+}
+
+// Ollama creates an Ollama client from the ollama flags of the command, at --llm-base-url if set.
+func Ollama(cmd *cobra.Command) (*ollama.Ollama, error) {
+	ollamaHost, err := cmd.Flags().GetString("ollama-host")
+	if err != nil {
+		return nil, err
+	}
+
+	llmBaseURL, err := cmd.Flags().GetString("llm-base-url")
+	if err != nil {
+		return nil, err
+	}
+
+	if llmBaseURL != "" {
+		ollamaHost = llmBaseURL
+	}
+
+	ollamaTimeout, err := cmd.Flags().GetDuration("ollama-timeout")
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := Options(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	keepAlive, err := cmd.Flags().GetString("keep-alive")
+	if err != nil {
+		return nil, err
+	}
+
+	return ollama.
+		New(ollamaHost).
+		WithTimeout(ollamaTimeout).
+		WithOptions(options).
+		WithKeepAlive(keepAlive), nil
+}
+
+// OllamaProvider creates an Ollama client from the ollama flags of the command,
+// failing if the --llm-provider is not Ollama, e.g. to manage its models.
+func OllamaProvider(cmd *cobra.Command) (*ollama.Ollama, error) {
+	provider, err := cmd.Flags().GetString("llm-provider")
+	if err != nil {
+		return nil, err
+	}
+
+	if provider != llm.Ollama {
+		return nil, fmt.Errorf("models are only managed with the %s provider, got %s", llm.Ollama, provider)
+	}
+	return Ollama(cmd)
+}
+
+// PullModel pulls the model with Ollama, writing its progress to stderr.
+func PullModel(cmd *cobra.Command, ollamaClient *ollama.Ollama, model string) error {
+	progressBar := ollama.NewProgressBar(os.Stderr, term.IsTerminal(int(os.Stderr.Fd())))
+	defer progressBar.Done()
+
+	return ollamaClient.Pull(cmd.Context(), model, progressBar.Update)
+}
+
+// EnsureModel pulls the model with Ollama unless it is available locally.
+func EnsureModel(cmd *cobra.Command, model string) error {
+	ollamaClient, err := OllamaProvider(cmd)
+	if err != nil {
+		return err
+	}
+
+	found, err := ollamaClient.HasModel(cmd.Context(), model)
+	if err != nil || found {
+		return err
+	}
+
+	zap.S().Infof("🔍 Model %s is missing", model)
+	return PullModel(cmd, ollamaClient, model)
+}
+
+// LLM creates the client of the --llm-provider of the command.
+func LLM(cmd *cobra.Command) (llm.LLM, error) {
+	return FormatLLM(cmd, nil)
+}
+
+// FormatLLM creates the client of the --llm-provider of the command, whose responses are constrained to the format,
+// either "json" or a JSON schema (unconstrained if nil).
+func FormatLLM(cmd *cobra.Command, format json.RawMessage) (llm.LLM, error) {
+	provider, err := cmd.Flags().GetString("llm-provider")
+	if err != nil {
+		return nil, err
+	}
+
+	switch provider {
+	case llm.Ollama:
+		ollamaClient, err := Ollama(cmd)
+		if err != nil {
+			return nil, err
+		}
+		return ollamaClient.WithFormat(format), nil
+	case llm.OpenAI:
+		llmBaseURL, err := cmd.Flags().GetString("llm-base-url")
+		if err != nil {
+			return nil, err
+		}
+
+		if llmBaseURL == "" {
+			return nil, fmt.Errorf("llm base url is required with the %s provider, set it with --llm-base-url, JP_LLM_BASE_URL or a config file profile", provider)
+		}
+
+		llmAPIKey, err := cmd.Flags().GetString("llm-api-key")
+		if err != nil {
+			return nil, err
+		}
+
+		ollamaTimeout, err := cmd.Flags().GetDuration("ollama-timeout")
+		if err != nil {
+			return nil, err
+		}
+
+		options, err := Options(cmd)
+		if err != nil {
+			return nil, err
+		}
+
+		return openai.
+			New(llmBaseURL, llmAPIKey).
+			WithTimeout(ollamaTimeout).
+			WithOptions(options).
+			WithFormat(format), nil
+	default:
+		return nil, fmt.Errorf("unknown llm provider %q, expected one of %s or %s", provider, llm.Ollama, llm.OpenAI)
+	}
+}
+
+// Options returns the generation options of the model, from the flags that were set only
+// so that the others are left to the defaults of the model.
+func Options(cmd *cobra.Command) (llm.Options, error) {
+	var options llm.Options
+	if cmd.Flags().Changed("temperature") {
+		temperature, err := cmd.Flags().GetFloat64("temperature")
+		if err != nil {
+			return llm.Options{}, err
+		}
+
+		if temperature < 0 {
+			return llm.Options{}, fmt.Errorf("temperature must not be negative, got %g", temperature)
+		}
+		options.Temperature = &temperature
+	}
+
+	if cmd.Flags().Changed("num-ctx") {
+		numCtx, err := cmd.Flags().GetInt("num-ctx")
+		if err != nil {
+			return llm.Options{}, err
+		}
+
+		if numCtx < 1 {
+			return llm.Options{}, fmt.Errorf("num-ctx must be at least 1, got %d", numCtx)
+		}
+		options.NumCtx = numCtx
+	}
+
+	if cmd.Flags().Changed("seed") {
+		seed, err := cmd.Flags().GetInt("seed")
+		if err != nil {
+			return llm.Options{}, err
+		}
+		options.Seed = &seed
+	}
+
+	if cmd.Flags().Changed("top-p") {
+		topP, err := cmd.Flags().GetFloat64("top-p")
+		if err != nil {
+			return llm.Options{}, err
+		}
+
+		if topP < 0 || topP > 1 {
+			return llm.Options{}, fmt.Errorf("top-p must be between 0 and 1, got %g", topP)
+		}
+		options.TopP = &topP
+	}
+
+	stop, err := cmd.Flags().GetStringSlice("stop")
+	if err != nil {
+		return llm.Options{}, err
+	}
+	options.Stop = stop
+
+	return options, nil
+}
+
+// Summarizer creates the summarizer of the Jira issues rendered for the model, from --strategy and --num-ctx,
+// whose intermediate prompts are generated with the client.
+// Unless --num-ctx is set, the context window is the one of the ollama model, or else the default of --num-ctx.
+func Summarizer(cmd *cobra.Command, llmClient llm.LLM, model string, render func(issues []jira.Issue) (string, error)) (*summarize.Summarizer, error) {
+	strategyName, err := cmd.Flags().GetString("strategy")
+	if err != nil {
+		return nil, err
+	}
+
+	strategy, err := summarize.ParseStrategy(strategyName)
+	if err != nil {
+		return nil, err
+	}
+
+	numCtx, err := cmd.Flags().GetInt("num-ctx")
+	if err != nil {
+		return nil, err
+	}
+
+	if ollamaClient, ok := llmClient.(*ollama.Ollama); ok && !cmd.Flags().Changed("num-ctx") {
+		info, err := ollamaClient.Show(cmd.Context(), model)
+		if err != nil {
+			return nil, err
+		}
+
+		if n := info.NumCtx(); n > 0 {
+			numCtx = n
+		}
+		zap.S().Debugf("Context window of %s model: %d tokens", model, numCtx)
+	}
+
+	return &summarize.Summarizer{
+		Strategy: strategy,
+		NumCtx:   numCtx,
+		Render:   render,
+		Generate: func(ctx context.Context, prompt string) (string, error) {
+			return llmClient.Generate(ctx, model, prompt, false, false)
+		},
+	}, nil
+}
+
+// FormatFlags adds the flags of the structured output of the model to the command.
+func FormatFlags(cmd *cobra.Command) {
+	cmd.Flags().String("format", "", "format of the response: json, in which case it is validated and printed as indented JSON")
+	cmd.Flags().String("schema", "", "JSON schema file the response is constrained to and validated against, implying --format json")
+	cmd.Flags().Int("schema-retries", schema.DefaultRetries, "number of times the model is asked to correct a response that is not valid JSON matching the schema")
+}
+
+// Schema returns the schema of the structured output of the command from --format and --schema, nil if free text.
+func Schema(cmd *cobra.Command) (*schema.Schema, error) {
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return nil, err
+	}
+
+	schemaFile, err := cmd.Flags().GetString("schema")
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case schemaFile != "":
+		if format != "" && format != "json" {
+			return nil, fmt.Errorf("unknown format %q with --schema, expected json", format)
+		}
+		return schema.Load(schemaFile)
+	case format == "json":
+		return schema.JSON, nil
+	case format == "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected json", format)
+	}
+}
+
+// IndexFlags adds the flags of the vector index of the Jira issues to the command.
+func IndexFlags(cmd *cobra.Command) {
+	cmd.Flags().String("index", "", "vector index file of the jira issues (default $XDG_CONFIG_HOME/jp/index.json)")
+	cmd.Flags().String("embed-model", vector.DefaultModel, "embedding model of the --llm-provider, with which the jira issues are indexed")
+}
+
+// IndexPath returns the path of the vector index of the command, from --index.
+func IndexPath(cmd *cobra.Command) (string, error) {
+	path, err := cmd.Flags().GetString("index")
+	if err != nil || path != "" {
+		return path, err
+	}
+
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "index.json"), nil
+}
+
+// Projection creates the projection of the Jira issues from --jira-include and --jira-excluded-fields.
+func Projection(cmd *cobra.Command) (jira.Projection, error) {
+	jiraInclude, err := cmd.Flags().GetString("jira-include")
+	if err != nil {
+		return jira.Projection{}, err
+	}
+
+	jiraExcludedFields, err := cmd.Flags().GetString("jira-excluded-fields")
+	if err != nil {
+		return jira.Projection{}, err
+	}
+
+	return jira.ParseProjection(jiraInclude, jiraExcludedFields)
+}
+
+// SearchFlags adds the flags composing a Jira search request to the command.
+func SearchFlags(cmd *cobra.Command) {
+	cmd.Flags().String("jql", "", "jira JQL query, combined with the other search flags")
+	cmd.Flags().StringSlice("project", nil, "jira projects of the issues (comma separated)")
+	cmd.Flags().StringSlice("status", nil, "jira statuses of the issues (comma separated)")
+	cmd.Flags().String("assignee", "", "jira assignee of the issues: me, unassigned, or an account id or name")
+	cmd.Flags().String("sprint", "", "jira sprint of the issues: current, future, closed, or a sprint id or name")
+	cmd.Flags().String("updated-since", "", "jira issues updated since a duration (e.g. 12h, 7d or 2w) or a date (e.g. 2024-01-31)")
+	cmd.Flags().StringSlice("fields", nil, "jira fields of the issues (comma separated), e.g. summary,status,assignee")
+	cmd.Flags().Bool("with-comments", false, "fetch the comments of each jira issue")
+	cmd.Flags().Bool("with-changelog", false, "fetch the changelog of each jira issue")
+	cmd.Flags().Bool("with-worklogs", false, "fetch the worklogs of each jira issue")
+	cmd.Flags().Int("jira-concurrency", jira.DefaultConcurrency, "maximum number of jira issues whose comments, changelog or worklogs are fetched at once")
+}
+
+// Details returns the details of the Jira issues fetched on top of the search results.
+func Details(cmd *cobra.Command) (jira.Details, error) {
+	var details jira.Details
+	var err error
+	if details.Comments, err = cmd.Flags().GetBool("with-comments"); err != nil {
+		return jira.Details{}, err
+	}
+
+	if details.Changelog, err = cmd.Flags().GetBool("with-changelog"); err != nil {
+		return jira.Details{}, err
+	}
+
+	if details.Worklogs, err = cmd.Flags().GetBool("with-worklogs"); err != nil {
+		return jira.Details{}, err
+	}
+
+	if details.Concurrency, err = cmd.Flags().GetInt("jira-concurrency"); err != nil {
+		return jira.Details{}, err
+	}
+
+	if details.Concurrency < 1 {
+		return jira.Details{}, fmt.Errorf("jira concurrency must be at least 1, got %d", details.Concurrency)
+	}
+	return details, nil
+}
+
+// Query returns the Jira query composed from the search flags of the command.
+func Query(cmd *cobra.Command) (jira.Query, error) {
+	var query jira.Query
+	var err error
+	if query.JQL, err = cmd.Flags().GetString("jql"); err != nil {
+		return jira.Query{}, err
+	}
+
+	if query.Projects, err = cmd.Flags().GetStringSlice("project"); err != nil {
+		return jira.Query{}, err
+	}
+
+	if query.Statuses, err = cmd.Flags().GetStringSlice("status"); err != nil {
+		return jira.Query{}, err
+	}
+
+	if query.Assignee, err = cmd.Flags().GetString("assignee"); err != nil {
+		return jira.Query{}, err
+	}
+
+	if query.Sprint, err = cmd.Flags().GetString("sprint"); err != nil {
+		return jira.Query{}, err
+	}
+
+	if query.UpdatedSince, err = cmd.Flags().GetString("updated-since"); err != nil {
+		return jira.Query{}, err
+	}
+
+	if query.Fields, err = cmd.Flags().GetStringSlice("fields"); err != nil {
+		return jira.Query{}, err
+	}
+	return query, nil
+}
+
+// SearchRequest creates the Jira search request of the command, composed from the search flags if any is set,
+// or else parsed from --jira-request, with --fields overriding its fields.
+func SearchRequest(cmd *cobra.Command) (jira.SearchRequest, error) {
+	query, err := Query(cmd)
+	if err != nil {
+		return jira.SearchRequest{}, err
+	}
+
+	jiraMaxIssues, err := cmd.Flags().GetInt("jira-max-issues")
+	if err != nil {
+		return jira.SearchRequest{}, err
+	}
+
+	var request jira.SearchRequest
+	if query.IsZero() {
+		jiraRequest, err := cmd.Flags().GetString("jira-request")
+		if err != nil {
+			return jira.SearchRequest{}, err
+		}
+
+		if request, err = jira.ParseSearchRequest(jiraRequest); err != nil {
+			return jira.SearchRequest{}, err
+		}
+
+		if len(query.Fields) > 0 {
+			request.Fields = query.Fields
+		}
+	} else if request, err = query.SearchRequest(); err != nil {
+		return jira.SearchRequest{}, err
+	}
+
+	request.MaxIssues = jiraMaxIssues
+	return request, nil
+}
+
+// TreeFlags adds the flags of a Jira issue tree to the command.
+func TreeFlags(cmd *cobra.Command, depth int) {
+	cmd.Flags().Int("depth", depth, "number of levels of related jira issues to fetch (0 for the issue only)")
+	cmd.Flags().StringSlice("expand", []string{string(jira.Subtasks), string(jira.Links), string(jira.Children)}, "relations of the jira issues to fetch (comma separated): subtasks, links or children")
+	cmd.Flags().StringSlice("fields", nil, "jira fields of the issues (comma separated), e.g. summary,status,assignee (all of them if empty)")
+}
+
+// Tree returns the Jira issue tree of the command.
+func Tree(cmd *cobra.Command) (jira.Tree, error) {
+	var tree jira.Tree
+	var err error
+	if tree.Depth, err = cmd.Flags().GetInt("depth"); err != nil {
+		return jira.Tree{}, err
+	}
+
+	if tree.Depth < 0 {
+		return jira.Tree{}, fmt.Errorf("depth must not be negative, got %d", tree.Depth)
+	}
+
+	expand, err := cmd.Flags().GetStringSlice("expand")
+	if err != nil {
+		return jira.Tree{}, err
+	}
+
+	if tree.Relations, err = jira.ParseRelations(expand); err != nil {
+		return jira.Tree{}, err
+	}
+
+	if tree.Fields, err = cmd.Flags().GetStringSlice("fields"); err != nil {
+		return jira.Tree{}, err
+	}
+	return tree, nil
+}
+
+// Board returns the Jira board of the --board flag of the command, as a board id or name.
+func Board(cmd *cobra.Command, jiraClient *jira.Jira) (*jira.Board, error) {
+	board, err := cmd.Flags().GetString("board")
+	if err != nil {
+		return nil, err
+	}
+
+	if board == "" {
+		return nil, errors.New("jira board is required, set it with --board, JP_BOARD or a config file profile")
+	}
+	return jiraClient.Board(cmd.Context(), board)
+}
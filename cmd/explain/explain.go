@@ -0,0 +1,82 @@
+package explain
+
+import (
+	"fmt"
+
+	"github.com/jhandguy/jira-prompt/cmd/client"
+	"github.com/jhandguy/jira-prompt/internal/jira"
+	"github.com/jhandguy/jira-prompt/internal/llm"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:           "explain KEY",
+	Short:         "Explain a Jira issue and its blockers with Ollama",
+	Args:          cobra.ExactArgs(1),
+	RunE:          explain,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+var (
+	ollamaPrompt, textFormat string
+	ollamaStream, ollamaRaw  bool
+)
+
+func init() {
+	client.TreeFlags(Cmd, 1)
+	Cmd.Flags().StringVarP(&ollamaPrompt, "ollama-prompt", "p", "Given the following JSON representation of a Jira issue with its subtasks, linked issues and children, explain in plain language the work it involves, how far along it is, and what blocks it:", "ollama text prompt")
+	Cmd.Flags().BoolVarP(&ollamaStream, "ollama-stream", "s", true, "enable ollama streaming")
+	Cmd.Flags().BoolVarP(&ollamaRaw, "ollama-raw", "r", false, "disable ollama formatting")
+	Cmd.Flags().StringVar(&textFormat, "text-format", string(jira.TextMarkdown), "format of the jira rich text fields, such as the description: raw, markdown or plain")
+}
+
+func explain(cmd *cobra.Command, args []string) error {
+	jiraTextFormat, err := jira.ParseTextFormat(textFormat)
+	if err != nil {
+		return err
+	}
+
+	tree, err := client.Tree(cmd)
+	if err != nil {
+		return err
+	}
+
+	projection, err := client.Projection(cmd)
+	if err != nil {
+		return err
+	}
+
+	jiraClient, err := client.Jira(cmd)
+	if err != nil {
+		return err
+	}
+
+	ollamaModel, err := cmd.InheritedFlags().GetString("ollama-model")
+	if err != nil {
+		return err
+	}
+
+	llmClient, err := client.LLM(cmd)
+	if err != nil {
+		return err
+	}
+
+	issue, err := jiraClient.IssueTree(cmd.Context(), args[0], tree)
+	if err != nil {
+		return err
+	}
+
+	jiraResponse, err := projection.Encode(jira.ConvertText([]jira.Issue{*issue}, jiraTextFormat)[0])
+	if err != nil {
+		return err
+	}
+
+	res, err := llm.Prompt(cmd.Context(), llmClient, ollamaModel, ollamaPrompt, jiraResponse, ollamaStream, ollamaRaw)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(res)
+	return nil
+}
@@ -0,0 +1,145 @@
+package index
+
+import (
+	"fmt"
+
+	"github.com/jhandguy/jira-prompt/cmd/client"
+	"github.com/jhandguy/jira-prompt/internal/jira"
+	"github.com/jhandguy/jira-prompt/internal/vector"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "index",
+	Short: "Index Jira issues for semantic search",
+	Long: `Index the Jira issues matching the search flags in a local vector index, embedding their summary, description and comments
+with the --embed-model of the --llm-provider, so that jp ask retrieves the issues relevant to a question.
+
+Indexing is incremental: only the issues that are not indexed yet, or were updated since, are embedded again.`,
+	Args:          cobra.NoArgs,
+	RunE:          index,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+var (
+	textFormat string
+	batchSize  int
+	prune      bool
+)
+
+func init() {
+	client.SearchFlags(Cmd)
+	client.IndexFlags(Cmd)
+	Cmd.Flags().IntVar(&batchSize, "embed-batch", 16, "number of jira issues embedded per request, after each of which the index is saved")
+	Cmd.Flags().BoolVar(&prune, "prune", false, "remove the indexed jira issues that no longer match the search")
+	Cmd.Flags().StringVar(&textFormat, "text-format", string(jira.TextMarkdown), "format of the jira rich text fields of the indexed issues, such as the description: raw, markdown or plain")
+}
+
+func index(cmd *cobra.Command, _ []string) error {
+	if batchSize < 1 {
+		return fmt.Errorf("embed batch must be at least 1, got %d", batchSize)
+	}
+
+	request, err := client.SearchRequest(cmd)
+	if err != nil {
+		return err
+	}
+
+	// The fields of --jira-request are meant for prompting, unlike the indexed ones
+	if !cmd.Flags().Changed("fields") {
+		request.Fields = nil
+	}
+	request.Fields = vector.SearchFields(request.Fields)
+
+	// The comments are always fetched, as they are embedded
+	details, err := client.Details(cmd)
+	if err != nil {
+		return err
+	}
+	details.Comments = true
+
+	jiraTextFormat, err := jira.ParseTextFormat(textFormat)
+	if err != nil {
+		return err
+	}
+
+	embedModel, err := cmd.Flags().GetString("embed-model")
+	if err != nil {
+		return err
+	}
+
+	path, err := client.IndexPath(cmd)
+	if err != nil {
+		return err
+	}
+
+	jiraClient, err := client.Jira(cmd)
+	if err != nil {
+		return err
+	}
+
+	llmClient, err := client.LLM(cmd)
+	if err != nil {
+		return err
+	}
+
+	idx, err := vector.Load(path)
+	if err != nil {
+		return err
+	}
+
+	if idx.Reset(embedModel) {
+		zap.S().Warnf("🔁 The index was embedded with another model, re-indexing every issue with %s", embedModel)
+	}
+
+	jiraIssues, err := jiraClient.SearchIssues(cmd.Context(), request)
+	if err != nil {
+		return err
+	}
+
+	// Only the details of the issues to embed are fetched
+	stale := idx.Stale(jiraIssues.Issues)
+	if err = jiraClient.FetchDetails(cmd.Context(), stale, details); err != nil {
+		return err
+	}
+
+	zap.S().Infof("🧮 Indexing %d of %d issues with %s model...", len(stale), len(jiraIssues.Issues), embedModel)
+	for start := 0; start < len(stale); start += batchSize {
+		batch := stale[start:min(start+batchSize, len(stale))]
+
+		documents := make([]string, len(batch))
+		for i, issue := range batch {
+			documents[i] = vector.Document(issue)
+		}
+
+		vectors, err := llmClient.Embed(cmd.Context(), embedModel, documents)
+		if err != nil {
+			return err
+		}
+
+		for i, issue := range jira.ConvertText(batch, jiraTextFormat) {
+			idx.Add(issue, vectors[i])
+		}
+
+		// The index is saved along the way, so that an interrupted indexing resumes where it stopped
+		if err = idx.Save(path); err != nil {
+			return err
+		}
+		zap.S().Debugf("Indexed %d of %d issues", start+len(batch), len(stale))
+	}
+
+	if prune {
+		if removed := idx.Prune(jiraIssues.Issues); removed > 0 {
+			zap.S().Infof("🧹 Pruned %d issues", removed)
+		}
+	}
+
+	if err = idx.Save(path); err != nil {
+		return err
+	}
+
+	zap.S().Infof("✅ Index up to date with %d issues!", len(idx.Entries))
+	return nil
+}
@@ -0,0 +1,62 @@
+package issue
+
+import (
+	"os"
+
+	"github.com/jhandguy/jira-prompt/cmd/client"
+	"github.com/jhandguy/jira-prompt/internal/jira"
+	"github.com/jhandguy/jira-prompt/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:           "issue KEY",
+	Short:         "Get a Jira issue with its related issues",
+	Args:          cobra.ExactArgs(1),
+	RunE:          issue,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+var outputFormat, textFormat string
+
+func init() {
+	client.TreeFlags(Cmd, 0)
+	Cmd.Flags().StringVar(&outputFormat, "output", string(output.JSON), "output format: json, pretty or yaml")
+	Cmd.Flags().StringVar(&textFormat, "text-format", string(jira.TextRaw), "format of the jira rich text fields, such as the description: raw, markdown or plain")
+}
+
+func issue(cmd *cobra.Command, args []string) error {
+	format, err := output.ParseIssueFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+
+	jiraTextFormat, err := jira.ParseTextFormat(textFormat)
+	if err != nil {
+		return err
+	}
+
+	tree, err := client.Tree(cmd)
+	if err != nil {
+		return err
+	}
+
+	projection, err := client.Projection(cmd)
+	if err != nil {
+		return err
+	}
+
+	jiraClient, err := client.Jira(cmd)
+	if err != nil {
+		return err
+	}
+
+	res, err := jiraClient.IssueTree(cmd.Context(), args[0], tree)
+	if err != nil {
+		return err
+	}
+
+	converted := jira.ConvertText([]jira.Issue{*res}, jiraTextFormat)[0]
+	return output.WriteIssue(os.Stdout, format, &converted, projection)
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jhandguy/jira-prompt/cmd/client"
+	"github.com/jhandguy/jira-prompt/internal/ollama"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "models",
+	Short: "Manage the Ollama models",
+	Long: `List, pull and show the models of Ollama.
+
+The model defaults to --ollama-model, and is pulled before prompting if missing with jp prompt --auto-pull.`,
+}
+
+var listCmd = &cobra.Command{
+	Use:           "list",
+	Short:         "List the models available locally",
+	Args:          cobra.NoArgs,
+	RunE:          list,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+var pullCmd = &cobra.Command{
+	Use:           "pull [MODEL]",
+	Short:         "Pull a model, or update it if available locally",
+	Args:          cobra.MaximumNArgs(1),
+	RunE:          pull,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+var showCmd = &cobra.Command{
+	Use:           "show [MODEL]",
+	Short:         "Show the information of a model",
+	Args:          cobra.MaximumNArgs(1),
+	RunE:          show,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+var modelfile bool
+
+func init() {
+	Cmd.AddCommand(listCmd)
+	Cmd.AddCommand(pullCmd)
+	Cmd.AddCommand(showCmd)
+
+	showCmd.Flags().BoolVar(&modelfile, "modelfile", false, "show the Modelfile of the model")
+}
+
+func list(cmd *cobra.Command, _ []string) error {
+	ollamaClient, err := client.OllamaProvider(cmd)
+	if err != nil {
+		return err
+	}
+
+	models, err := ollamaClient.Models(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tSIZE\tPARAMETERS\tQUANTIZATION\tMODIFIED")
+	for _, m := range models {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", m.Name, ollama.FormatSize(m.Size), m.Details.ParameterSize, m.Details.QuantizationLevel, m.ModifiedAt.Format("2006-01-02 15:04"))
+	}
+	return w.Flush()
+}
+
+func pull(cmd *cobra.Command, args []string) error {
+	model, err := modelArg(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	ollamaClient, err := client.OllamaProvider(cmd)
+	if err != nil {
+		return err
+	}
+
+	return client.PullModel(cmd, ollamaClient, model)
+}
+
+func show(cmd *cobra.Command, args []string) error {
+	model, err := modelArg(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	ollamaClient, err := client.OllamaProvider(cmd)
+	if err != nil {
+		return err
+	}
+
+	info, err := ollamaClient.Show(cmd.Context(), model)
+	if err != nil {
+		return err
+	}
+
+	if modelfile {
+		fmt.Print(info.Modelfile)
+		return nil
+	}
+
+	architecture, _ := info.ModelInfo["general.architecture"].(string)
+	contextLength := ""
+	if n := info.ContextLength(); n > 0 {
+		contextLength = strconv.Itoa(n)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Model\t%s\n", model)
+	fmt.Fprintf(w, "Architecture\t%s\n", architecture)
+	fmt.Fprintf(w, "Parameters\t%s\n", info.Details.ParameterSize)
+	fmt.Fprintf(w, "Quantization\t%s\n", info.Details.QuantizationLevel)
+	fmt.Fprintf(w, "Context length\t%s\n", contextLength)
+	fmt.Fprintf(w, "Capabilities\t%s\n", strings.Join(info.Capabilities, ", "))
+	fmt.Fprintf(w, "Modified\t%s\n", info.ModifiedAt.Format("2006-01-02 15:04"))
+	if err = w.Flush(); err != nil {
+		return err
+	}
+
+	// The parameters of the Modelfile, such as stop sequences, are given one per line
+	if info.Parameters != "" {
+		fmt.Println("\nParameters:")
+		for _, line := range strings.Split(strings.TrimSpace(info.Parameters), "\n") {
+			fmt.Printf("  %s\n", strings.Join(strings.Fields(line), " "))
+		}
+	}
+	return nil
+}
+
+// modelArg returns the model given as argument, or else --ollama-model.
+func modelArg(cmd *cobra.Command, args []string) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	return cmd.Flags().GetString("ollama-model")
+}
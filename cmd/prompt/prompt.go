@@ -1,11 +1,20 @@
 package prompt
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
+	"slices"
 
+	"github.com/jhandguy/jira-prompt/cmd/client"
+	"github.com/jhandguy/jira-prompt/internal/config"
 	"github.com/jhandguy/jira-prompt/internal/jira"
-	"github.com/jhandguy/jira-prompt/internal/ollama"
+	"github.com/jhandguy/jira-prompt/internal/llm"
+	"github.com/jhandguy/jira-prompt/internal/render"
+	"github.com/jhandguy/jira-prompt/internal/schema"
+	"github.com/jhandguy/jira-prompt/internal/summarize"
 	"github.com/spf13/cobra"
+	"go.uber.org/zap"
 )
 
 var Cmd = &cobra.Command{
@@ -17,49 +26,118 @@ var Cmd = &cobra.Command{
 }
 
 var (
-	ollamaHost, ollamaModel, ollamaPrompt string
-	ollamaStream, ollamaRaw               bool
+	ollamaPrompt, promptTemplate, system, textFormat string
+	ollamaStream, ollamaRaw, autoPull                bool
 )
 
 func init() {
-	Cmd.Flags().StringVarP(&ollamaHost, "ollama-host", "o", "http://127.0.0.1:11434", "ollama host url")
-	Cmd.Flags().StringVarP(&ollamaModel, "ollama-model", "m", "llama3", "ollama AI model")
-	Cmd.Flags().StringVarP(&ollamaPrompt, "ollama-prompt", "p", "Given the following JSON representation of a Jira board, describe what the Forge team is working on:", "ollama text prompt")
+	client.SearchFlags(Cmd)
+	client.FormatFlags(Cmd)
+	Cmd.Flags().StringVarP(&ollamaPrompt, "ollama-prompt", "p", "Given the following JSON representation of a Jira board, describe what the team is working on:", "ollama text prompt")
 	Cmd.Flags().BoolVarP(&ollamaStream, "ollama-stream", "s", true, "enable ollama streaming")
 	Cmd.Flags().BoolVarP(&ollamaRaw, "ollama-raw", "r", false, "disable ollama formatting")
+	Cmd.Flags().StringVar(&system, "system", "", "system prompt, followed by the Jira data, in which case the text prompt is sent as the user message")
+	Cmd.Flags().String("strategy", string(summarize.Stuff), "strategy for Jira data exceeding the context window (stuff, map-reduce or refine)")
+	Cmd.Flags().StringVar(&textFormat, "text-format", string(jira.TextMarkdown), "format of the jira rich text fields, such as the description: raw, markdown or plain")
+	Cmd.Flags().String("board", "", "jira board id or name, with which the --sprint issues are fetched along with the sprint goal and dates")
+	Cmd.Flags().BoolVar(&autoPull, "auto-pull", false, "pull the ollama model before prompting if it is missing")
+	Cmd.Flags().StringVar(&promptTemplate, "prompt-template", "", "prompt template file, or name of a template in $XDG_CONFIG_HOME/jp/templates")
 }
 
 func prompt(cmd *cobra.Command, _ []string) error {
-	jiraURL, err := cmd.InheritedFlags().GetString("jira-url")
+	request, err := client.SearchRequest(cmd)
 	if err != nil {
 		return err
 	}
 
-	jiraToken, err := cmd.InheritedFlags().GetString("jira-token")
+	details, err := client.Details(cmd)
 	if err != nil {
 		return err
 	}
 
-	jiraRequest, err := cmd.InheritedFlags().GetString("jira-request")
+	jiraClient, err := client.Jira(cmd)
 	if err != nil {
 		return err
 	}
 
-	jiraExcludedFields, err := cmd.InheritedFlags().GetString("jira-excluded-fields")
+	projection, err := client.Projection(cmd)
 	if err != nil {
 		return err
 	}
 
-	jiraResponse, err := jira.
-		New(jiraURL, jiraToken).
-		Search(jiraRequest, jiraExcludedFields)
+	ollamaModel, err := cmd.InheritedFlags().GetString("ollama-model")
 	if err != nil {
 		return err
 	}
 
-	res, err := ollama.
-		New(ollamaHost).
-		Prompt(ollamaModel, ollamaPrompt, jiraResponse, ollamaStream, ollamaRaw)
+	llmClient, err := client.LLM(cmd)
+	if err != nil {
+		return err
+	}
+
+	if autoPull {
+		if err = client.EnsureModel(cmd, ollamaModel); err != nil {
+			return err
+		}
+	}
+
+	structured, err := client.Schema(cmd)
+	if err != nil {
+		return err
+	}
+
+	schemaRetries, err := cmd.Flags().GetInt("schema-retries")
+	if err != nil {
+		return err
+	}
+
+	if schemaRetries < 0 {
+		return fmt.Errorf("schema retries must not be negative, got %d", schemaRetries)
+	}
+
+	jiraTextFormat, err := jira.ParseTextFormat(textFormat)
+	if err != nil {
+		return err
+	}
+
+	// The text prompt is completed with the sprint, if any, once fetched
+	data := &render.Data{Prompt: ollamaPrompt}
+	renderIssues, err := issuesRenderer(projection, data)
+	if err != nil {
+		return err
+	}
+
+	summarizer, err := client.Summarizer(cmd, llmClient, ollamaModel, renderIssues)
+	if err != nil {
+		return err
+	}
+
+	jiraIssues, sprint, err := searchIssues(cmd, jiraClient, request)
+	if err != nil {
+		return err
+	}
+
+	if sprint != nil {
+		data.Prompt = fmt.Sprintf("%s\n%s", ollamaPrompt, sprint.Describe())
+		data.Sprint = sprint
+	}
+
+	if err = jiraClient.FetchDetails(cmd.Context(), jiraIssues.Issues, details); err != nil {
+		return err
+	}
+
+	jiraIssues.Issues = jira.ConvertText(jiraIssues.Issues, jiraTextFormat)
+
+	llmRequest, err := summarizer.Request(cmd.Context(), system, data.Prompt, promptTemplate != "", jiraIssues.Issues)
+	if err != nil {
+		return err
+	}
+
+	if structured != nil {
+		return promptStructured(cmd, structured, schemaRetries, ollamaModel, llmRequest)
+	}
+
+	res, err := llm.Respond(cmd.Context(), llmClient, ollamaModel, llmRequest, ollamaStream, ollamaRaw)
 	if err != nil {
 		return err
 	}
@@ -67,3 +145,111 @@ func prompt(cmd *cobra.Command, _ []string) error {
 	fmt.Print(res)
 	return nil
 }
+
+// promptStructured prompts the model for JSON matching the schema, asking it to correct any invalid response,
+// and prints the validated JSON.
+func promptStructured(cmd *cobra.Command, s *schema.Schema, retries int, model string, request llm.Request) error {
+	llmClient, err := client.FormatLLM(cmd, s.Format())
+	if err != nil {
+		return err
+	}
+
+	// The response is validated as a whole, so it is never streamed
+	res, err := s.Chat(cmd.Context(), func(ctx context.Context, messages []llm.Message) (llm.Message, error) {
+		return llmClient.Chat(ctx, model, messages, false)
+	}, request.Messages(), retries)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(res)
+	return nil
+}
+
+// searchIssues searches the Jira issues of the request, or else fetches the issues of the --sprint of the --board
+// if both are set, filtered by the other search flags, in which case the sprint is returned too.
+func searchIssues(cmd *cobra.Command, jiraClient *jira.Jira, request jira.SearchRequest) (*jira.SearchResponse, *jira.Sprint, error) {
+	board, err := cmd.Flags().GetString("board")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sprintName, err := cmd.Flags().GetString("sprint")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if board == "" || sprintName == "" {
+		if sprintName != "" {
+			zap.S().Warnf("⚠️ Sprint %q is searched by JQL, across every board and without its goal and dates, set --board, JP_BOARD or a config file profile to fetch it from a board", sprintName)
+		}
+
+		res, err := jiraClient.SearchIssues(cmd.Context(), request)
+		return res, nil, err
+	}
+
+	jiraBoard, err := client.Board(cmd, jiraClient)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The sprint clause of the request is replaced by the sprint of the board
+	query, err := client.Query(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var jql string
+	if query.Sprint = ""; !query.IsZero() {
+		sprintRequest, err := query.SearchRequest()
+		if err != nil {
+			return nil, nil, err
+		}
+		jql = sprintRequest.JQL
+	}
+
+	// The states of the issues are always given along with the sprint
+	fields := request.Fields
+	if len(fields) > 0 && !slices.Contains(fields, "status") {
+		fields = append(slices.Clone(fields), "status")
+	}
+
+	report, err := jiraClient.FetchSprint(cmd.Context(), jiraBoard.ID, sprintName, jql, fields, request.MaxIssues)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &jira.SearchResponse{Issues: report.Issues}, &report.Sprint, nil
+}
+
+// issuesRenderer returns how Jira issues are rendered with the fields of the projection,
+// with the prompt template over the data or else as JSON.
+func issuesRenderer(projection jira.Projection, data *render.Data) (func(issues []jira.Issue) (string, error), error) {
+	if promptTemplate == "" {
+		return func(issues []jira.Issue) (string, error) {
+			return projection.Encode(&jira.SearchResponse{Issues: issues})
+		}, nil
+	}
+
+	dir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := render.Load(promptTemplate, filepath.Join(dir, "templates"))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(issues []jira.Issue) (string, error) {
+		issues, err := projection.Issues(issues)
+		if err != nil {
+			return "", err
+		}
+
+		return tmpl.Execute(render.Data{
+			Prompt: data.Prompt,
+			Issues: issues,
+			Sprint: data.Sprint,
+		})
+	}, nil
+}
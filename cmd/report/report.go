@@ -0,0 +1,187 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jhandguy/jira-prompt/cmd/client"
+	"github.com/jhandguy/jira-prompt/internal/config"
+	"github.com/jhandguy/jira-prompt/internal/jira"
+	"github.com/jhandguy/jira-prompt/internal/llm"
+	"github.com/jhandguy/jira-prompt/internal/recipe"
+	"github.com/jhandguy/jira-prompt/internal/render"
+	"github.com/jhandguy/jira-prompt/internal/summarize"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "report [RECIPE]",
+	Short: "Report on Jira issues with a recipe",
+	Long: `Report on Jira issues with a recipe, defining the JQL, fields, system prompt, text prompt and template of the report.
+
+Recipes are bundled for the daily standup, sprint review, release notes, risk scan and workload,
+and custom recipes are loaded from --recipes-dir. Without a recipe, the recipes are listed.`,
+	Args:          cobra.MaximumNArgs(1),
+	RunE:          report,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+var (
+	recipesDir, textFormat string
+	vars                   map[string]string
+	ollamaStream           bool
+	concurrency            int
+)
+
+func init() {
+	Cmd.Flags().StringToStringVar(&vars, "var", nil, "variables of the recipe (e.g. --var version=1.2.0,project=PROJ)")
+	Cmd.Flags().StringVar(&recipesDir, "recipes-dir", "", "directory of the custom recipes (default $XDG_CONFIG_HOME/jp/recipes)")
+	Cmd.Flags().BoolVarP(&ollamaStream, "ollama-stream", "s", true, "enable ollama streaming")
+	Cmd.Flags().String("strategy", string(summarize.Stuff), "strategy for Jira data exceeding the context window (stuff, map-reduce or refine)")
+	Cmd.Flags().StringVar(&textFormat, "text-format", string(jira.TextMarkdown), "format of the jira rich text fields, such as the description: raw, markdown or plain")
+	Cmd.Flags().IntVar(&concurrency, "jira-concurrency", jira.DefaultConcurrency, "maximum number of jira issues whose comments, changelog or worklogs are fetched at once")
+}
+
+func report(cmd *cobra.Command, args []string) error {
+	if recipesDir == "" {
+		dir, err := config.Dir()
+		if err != nil {
+			return err
+		}
+		recipesDir = filepath.Join(dir, "recipes")
+	}
+
+	recipes, err := recipe.Load(recipesDir)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return list(recipes)
+	}
+
+	found, err := recipe.Find(recipes, args[0])
+	if err != nil {
+		return err
+	}
+
+	r, err := found.Resolve(vars)
+	if err != nil {
+		return err
+	}
+
+	if concurrency < 1 {
+		return fmt.Errorf("jira concurrency must be at least 1, got %d", concurrency)
+	}
+
+	details, err := r.Details(concurrency)
+	if err != nil {
+		return err
+	}
+
+	jiraMaxIssues, err := cmd.Flags().GetInt("jira-max-issues")
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := r.PromptTemplate()
+	if err != nil {
+		return err
+	}
+
+	jiraTextFormat, err := jira.ParseTextFormat(textFormat)
+	if err != nil {
+		return err
+	}
+
+	jiraClient, err := client.Jira(cmd)
+	if err != nil {
+		return err
+	}
+
+	projection, err := client.Projection(cmd)
+	if err != nil {
+		return err
+	}
+
+	ollamaModel, err := cmd.InheritedFlags().GetString("ollama-model")
+	if err != nil {
+		return err
+	}
+
+	llmClient, err := client.LLM(cmd)
+	if err != nil {
+		return err
+	}
+
+	summarizer, err := client.Summarizer(cmd, llmClient, ollamaModel, issuesRenderer(r.Prompt, tmpl, projection))
+	if err != nil {
+		return err
+	}
+
+	jiraIssues, err := jiraClient.SearchIssues(cmd.Context(), r.SearchRequest(jiraMaxIssues))
+	if err != nil {
+		return err
+	}
+
+	if err = jiraClient.FetchDetails(cmd.Context(), jiraIssues.Issues, details); err != nil {
+		return err
+	}
+
+	llmRequest, err := summarizer.Request(cmd.Context(), r.System, r.Prompt, tmpl != nil, jira.ConvertText(jiraIssues.Issues, jiraTextFormat))
+	if err != nil {
+		return err
+	}
+
+	res, err := llm.Respond(cmd.Context(), llmClient, ollamaModel, llmRequest, ollamaStream, false)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(res)
+	return nil
+}
+
+// issuesRenderer returns how Jira issues are rendered with the fields of the projection,
+// with the template of the recipe or else as JSON.
+func issuesRenderer(textPrompt string, tmpl *render.Template, projection jira.Projection) func(issues []jira.Issue) (string, error) {
+	if tmpl == nil {
+		return func(issues []jira.Issue) (string, error) {
+			return projection.Encode(&jira.SearchResponse{Issues: issues})
+		}
+	}
+
+	return func(issues []jira.Issue) (string, error) {
+		issues, err := projection.Issues(issues)
+		if err != nil {
+			return "", err
+		}
+
+		return tmpl.Execute(render.Data{
+			Prompt: textPrompt,
+			Issues: issues,
+		})
+	}
+}
+
+// list prints the recipes with their descriptions and variables.
+func list(recipes []recipe.Recipe) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RECIPE\tDESCRIPTION\tVARIABLES")
+	for _, r := range recipes {
+		names := make([]string, 0, len(r.Vars))
+		for _, v := range r.Vars {
+			if v.Required {
+				names = append(names, v.Name+" (required)")
+				continue
+			}
+			names = append(names, v.Name)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", r.Name, r.Description, strings.Join(names, ", "))
+	}
+	return w.Flush()
+}
@@ -1,44 +1,161 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/jhandguy/jira-prompt/cmd/ask"
+	"github.com/jhandguy/jira-prompt/cmd/auth"
+	"github.com/jhandguy/jira-prompt/cmd/chat"
+	"github.com/jhandguy/jira-prompt/cmd/explain"
+	"github.com/jhandguy/jira-prompt/cmd/index"
+	"github.com/jhandguy/jira-prompt/cmd/issue"
+	"github.com/jhandguy/jira-prompt/cmd/models"
 	"github.com/jhandguy/jira-prompt/cmd/prompt"
+	"github.com/jhandguy/jira-prompt/cmd/report"
 	"github.com/jhandguy/jira-prompt/cmd/search"
+	"github.com/jhandguy/jira-prompt/cmd/sprint"
+	"github.com/jhandguy/jira-prompt/internal/config"
+	"github.com/jhandguy/jira-prompt/internal/jira"
+	"github.com/jhandguy/jira-prompt/internal/llm"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
-var debug bool
+var (
+	debug               bool
+	configFile, profile string
+)
 
 var cmd = &cobra.Command{
 	Use:   "jp",
 	Short: "CLI to prompt Ollama with Jira data",
-	Long:  "jira-prompt is a CLI to prompt Ollama using data from Jira issues.",
+	Long: `jira-prompt is a CLI to prompt Ollama using data from Jira issues.
+
+Flags are resolved in the following order of precedence:
+  1. command line flags
+  2. environment variables prefixed with JP_ (e.g. JP_JIRA_TOKEN for --jira-token)
+  3. the selected profile of the config file
+  4. default values`,
 	CompletionOptions: cobra.CompletionOptions{
 		DisableDefaultCmd: true,
 	},
+	PersistentPreRunE: setup,
 }
 
 func init() {
-	cobra.OnInitialize(setup)
-
 	cmd.AddCommand(search.Cmd)
 	cmd.AddCommand(prompt.Cmd)
+	cmd.AddCommand(chat.Cmd)
+	cmd.AddCommand(issue.Cmd)
+	cmd.AddCommand(explain.Cmd)
+	cmd.AddCommand(sprint.Cmd)
+	cmd.AddCommand(report.Cmd)
+	cmd.AddCommand(index.Cmd)
+	cmd.AddCommand(ask.Cmd)
+	cmd.AddCommand(models.Cmd)
+	cmd.AddCommand(auth.Cmd)
 
 	cmd.PersistentFlags().BoolVarP(&debug, "debug", "d", false, "debug for jp")
-	cmd.PersistentFlags().StringP("jira-url", "u", "https://ecosystem.atlassian.net", "jira base url")
-	cmd.PersistentFlags().StringP("jira-token", "t", "", "jira API token")
-	cmd.PersistentFlags().StringP("jira-request", "q", "{\"jql\": \"project = FRGE AND status = \\\"In Progress\\\"\", \"fields\": [\"summary\"]}", "jira search request")
-	cmd.PersistentFlags().StringP("jira-excluded-fields", "e", "id,self,expand", "jira fields to exclude from the response (comma separated)")
+	cmd.PersistentFlags().StringVarP(&configFile, "config", "c", "", "config file (default $XDG_CONFIG_HOME/jp/config.yaml)")
+	cmd.PersistentFlags().StringVarP(&profile, "profile", "P", "", "config file profile (default \"default\")")
+	cmd.PersistentFlags().StringP("jira-url", "u", "", "jira base url")
+	cmd.PersistentFlags().StringP("jira-token", "t", "", "jira API token, personal access token or bearer token (default from the credential store)")
+	cmd.PersistentFlags().String("jira-auth", "basic", "jira auth: basic (with --jira-email, or a base64 encoded email:token), bearer, pat or oauth2")
+	cmd.PersistentFlags().String("jira-email", "", "jira account email, composed with the API token for basic auth")
+	cmd.PersistentFlags().String("jira-oauth-token-url", jira.DefaultOAuth2TokenURL, "jira OAuth 2.0 token url")
+	cmd.PersistentFlags().String("jira-oauth-client-id", "", "jira OAuth 2.0 client id")
+	cmd.PersistentFlags().String("jira-oauth-client-secret", "", "jira OAuth 2.0 client secret")
+	cmd.PersistentFlags().String("jira-oauth-refresh-token", "", "jira OAuth 2.0 refresh token (client credentials are granted if empty)")
+	cmd.PersistentFlags().String("credential-store", "file", "store of the jira credentials: file, encrypted (with JP_CREDENTIAL_PASSPHRASE) or helper:<command> (e.g. helper:osxkeychain)")
+	cmd.PersistentFlags().StringP("jira-request", "q", "{\"jql\": \"status = \\\"In Progress\\\"\", \"fields\": [\"summary\"]}", "jira search request, used if none of the --jql, --project, --status, --assignee, --sprint or --updated-since flags is set")
+	cmd.PersistentFlags().StringP("jira-excluded-fields", "e", "id,self,expand", "jira fields to exclude from the response (comma separated), by name at any depth or by path from each issue (e.g. fields.status.statusCategory)")
+	cmd.PersistentFlags().String("jira-include", "", "jira field paths from each issue to keep in the response (comma separated), e.g. fields.summary,fields.status.name,fields.*.displayName")
+	cmd.PersistentFlags().Int("jira-api-version", jira.APIv2, "version of the jira REST API: 2, returning rich text fields as wiki markup, or 3, returning them as Atlassian Document Format (Jira Cloud)")
+	cmd.PersistentFlags().Duration("jira-timeout", time.Minute, "timeout of each jira request (0 for no timeout)")
+	cmd.PersistentFlags().Int("jira-retries", jira.DefaultRetryCount, "number of retries of rate limited or failed jira requests (0 for no retry)")
+	cmd.PersistentFlags().Duration("jira-retry-wait", jira.DefaultRetryWaitTime, "initial wait of the exponential backoff between jira retries")
+	cmd.PersistentFlags().Duration("jira-retry-max-wait", jira.DefaultRetryMaxWaitTime, "maximum wait between jira retries, including when told by jira")
+	cmd.PersistentFlags().StringP("ollama-host", "o", "http://127.0.0.1:11434", "ollama host url")
+	cmd.PersistentFlags().StringP("ollama-model", "m", "llama3", "ollama AI model, or model of the --llm-provider")
+	cmd.PersistentFlags().Duration("ollama-timeout", 0, "timeout of each ollama or --llm-provider request, including streaming the response (0 for no timeout)")
+	cmd.PersistentFlags().Float64("temperature", 0, "temperature of the model, higher being more creative (default of the model if not set)")
+	cmd.PersistentFlags().Int("num-ctx", 4096, "size of the context window of the model in tokens, over which jira data is summarized, sent to ollama if set, or else read from the ollama model")
+	cmd.PersistentFlags().Int("seed", 0, "seed of the model, for reproducible responses (random if not set)")
+	cmd.PersistentFlags().Float64("top-p", 0, "cumulative probability of the tokens sampled by the model (default of the model if not set)")
+	cmd.PersistentFlags().StringSlice("stop", nil, "sequences at which the model stops generating (comma separated)")
+	cmd.PersistentFlags().String("keep-alive", "", "how long ollama keeps the model loaded after a request, e.g. 10m, or -1 to keep it loaded (default of ollama if empty)")
+	cmd.PersistentFlags().String("llm-provider", llm.Ollama, "llm provider: ollama, or openai for an OpenAI-compatible server such as llama.cpp server, vLLM or LocalAI")
+	cmd.PersistentFlags().String("llm-base-url", "", "base url of the llm provider, without /v1 (default --ollama-host with ollama)")
+	cmd.PersistentFlags().String("llm-api-key", "", "API key of the llm provider, if required by an OpenAI-compatible server")
+	cmd.PersistentFlags().Int("jira-max-issues", 0, "maximum number of jira issues to fetch across pages (0 for no limit)")
 }
 
-func setup() {
+func setup(cmd *cobra.Command, _ []string) error {
+	// The logger is set up even if the config fails, so that the error gets logged
+	values, err := setupConfig(cmd)
+
 	if err := setupLogger(); err != nil {
 		fmt.Printf("failed to setup logger: %v", err)
 	}
+
+	for _, unknown := range config.Unknown(values, knownFlag(cmd.Root())) {
+		zap.S().Warnf("⚠️ Ignoring %s, which matches no flag", unknown)
+	}
+	return err
+}
+
+func setupConfig(cmd *cobra.Command) (map[string]string, error) {
+	// Environment variables are applied first, as they may select the config file and profile
+	if err := config.Apply(cmd.Flags(), nil); err != nil {
+		return nil, err
+	}
+
+	path, required := configFile, configFile != ""
+	if !required {
+		var err error
+		if path, err = config.Path(); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg, err := config.Load(path, required)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := cfg.Values(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	return values, config.Apply(cmd.Flags(), values)
+}
+
+// knownFlag reports whether a flag of any command has the given name, as profiles and environment variables are
+// shared by the commands, or the name is the one of the credential passphrase, only set in the environment.
+func knownFlag(root *cobra.Command) func(name string) bool {
+	return func(name string) bool {
+		if name == "credential-passphrase" {
+			return true
+		}
+
+		commands := []*cobra.Command{root}
+		for len(commands) > 0 {
+			c := commands[0]
+			commands = append(commands[1:], c.Commands()...)
+			if c.Flags().Lookup(name) != nil || c.PersistentFlags().Lookup(name) != nil {
+				return true
+			}
+		}
+		return false
+	}
 }
 
 func setupLogger() error {
@@ -64,8 +181,19 @@ func setupLogger() error {
 }
 
 func Execute(version string) {
+	// Interrupting jp cancels the ongoing requests instead of killing it
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	cmd.Version = version
-	if err := cmd.Execute(); err != nil {
+	if err := cmd.ExecuteContext(ctx); err != nil {
+		if errors.Is(err, context.Canceled) {
+			// Ends any partially streamed output
+			fmt.Println()
+			zap.S().Warnf("🛑 Interrupted!")
+			stop()
+			os.Exit(130)
+		}
 		zap.S().Fatalf("❌ %v", err)
 	}
 }
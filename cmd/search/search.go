@@ -1,9 +1,12 @@
 package search
 
 import (
-	"fmt"
+	"os"
+	"strings"
 
+	"github.com/jhandguy/jira-prompt/cmd/client"
 	"github.com/jhandguy/jira-prompt/internal/jira"
+	"github.com/jhandguy/jira-prompt/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -15,34 +18,56 @@ var Cmd = &cobra.Command{
 	SilenceErrors: true,
 }
 
+var outputFormat, columns, textFormat string
+
+func init() {
+	client.SearchFlags(Cmd)
+	Cmd.Flags().StringVar(&outputFormat, "output", string(output.JSON), "output format: json, pretty, table, csv, markdown, yaml or jsonl")
+	Cmd.Flags().StringVar(&textFormat, "text-format", string(jira.TextRaw), "format of the jira rich text fields, such as the description: raw, markdown or plain")
+	Cmd.Flags().StringVar(&columns, "columns", strings.Join(output.DefaultColumns, ","), "issue fields of the table, csv and markdown outputs (comma separated), e.g. key,summary,status.name,labels")
+}
+
 func search(cmd *cobra.Command, _ []string) error {
-	jiraURL, err := cmd.InheritedFlags().GetString("jira-url")
+	format, err := output.ParseFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+
+	jiraTextFormat, err := jira.ParseTextFormat(textFormat)
 	if err != nil {
 		return err
 	}
 
-	jiraToken, err := cmd.InheritedFlags().GetString("jira-token")
+	request, err := client.SearchRequest(cmd)
 	if err != nil {
 		return err
 	}
 
-	jiraRequest, err := cmd.InheritedFlags().GetString("jira-request")
+	details, err := client.Details(cmd)
 	if err != nil {
 		return err
 	}
 
-	jiraExcludedFields, err := cmd.InheritedFlags().GetString("jira-excluded-fields")
+	jiraClient, err := client.Jira(cmd)
 	if err != nil {
 		return err
 	}
 
-	res, err := jira.
-		New(jiraURL, jiraToken).
-		Search(jiraRequest, jiraExcludedFields)
+	projection, err := client.Projection(cmd)
 	if err != nil {
 		return err
 	}
 
-	fmt.Print(res)
-	return nil
+	res, err := jiraClient.SearchIssues(cmd.Context(), request)
+	if err != nil {
+		return err
+	}
+
+	if err = jiraClient.FetchDetails(cmd.Context(), res.Issues, details); err != nil {
+		return err
+	}
+
+	res.Issues = jira.ConvertText(res.Issues, jiraTextFormat)
+
+	return output.Write(os.Stdout, format, res, projection, output.ParseColumns(columns))
 }
@@ -0,0 +1,129 @@
+package sprint
+
+import (
+	"errors"
+	"os"
+
+	"github.com/jhandguy/jira-prompt/cmd/client"
+	"github.com/jhandguy/jira-prompt/internal/jira"
+	"github.com/jhandguy/jira-prompt/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "sprint",
+	Short: "Show the sprints of a Jira board",
+	Long: `Show the sprints of a Jira Agile board, set with --board as a board id or name.
+
+The board can be set once in a config file profile, e.g. board: "Team board".`,
+}
+
+var listCmd = &cobra.Command{
+	Use:           "list",
+	Short:         "List the sprints of the board",
+	RunE:          list,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+var showCmd = &cobra.Command{
+	Use:           "show",
+	Short:         "Show a sprint of the board with its issues",
+	RunE:          show,
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+var (
+	listFormat, showFormat, sprint, textFormat string
+	states, fields                             []string
+	active                                     bool
+)
+
+func init() {
+	Cmd.AddCommand(listCmd)
+	Cmd.AddCommand(showCmd)
+
+	Cmd.PersistentFlags().String("board", "", "jira board id or name")
+
+	listCmd.Flags().StringSliceVar(&states, "state", nil, "states of the sprints (comma separated): active, future or closed (all of them if empty)")
+	listCmd.Flags().StringVar(&listFormat, "output", string(output.Table), "output format: table, csv, markdown, json, pretty, yaml or jsonl")
+
+	showCmd.Flags().StringVar(&showFormat, "output", string(output.JSON), "output format: json, pretty or yaml")
+	showCmd.Flags().BoolVar(&active, "active", false, "show the active sprint")
+	showCmd.Flags().StringVar(&sprint, "sprint", "", "sprint id or name, or current, open, future or closed for the only sprint in that state")
+	showCmd.Flags().StringSliceVar(&fields, "fields", []string{"summary", "status", "assignee", "issuetype", "priority"}, "jira fields of the issues (comma separated)")
+	showCmd.Flags().StringVar(&textFormat, "text-format", string(jira.TextRaw), "format of the jira rich text fields, such as the description: raw, markdown or plain")
+	showCmd.MarkFlagsMutuallyExclusive("active", "sprint")
+}
+
+func list(cmd *cobra.Command, _ []string) error {
+	format, err := output.ParseFormat(listFormat)
+	if err != nil {
+		return err
+	}
+
+	jiraClient, err := client.Jira(cmd)
+	if err != nil {
+		return err
+	}
+
+	board, err := client.Board(cmd, jiraClient)
+	if err != nil {
+		return err
+	}
+
+	sprints, err := jiraClient.Sprints(cmd.Context(), board.ID, states...)
+	if err != nil {
+		return err
+	}
+
+	return output.WriteSprints(os.Stdout, format, sprints)
+}
+
+func show(cmd *cobra.Command, _ []string) error {
+	if !active && sprint == "" {
+		return errors.New("a sprint is required, set it with --active or --sprint")
+	}
+	if active {
+		sprint = jira.SprintActive
+	}
+
+	format, err := output.ParseIssueFormat(showFormat)
+	if err != nil {
+		return err
+	}
+
+	jiraTextFormat, err := jira.ParseTextFormat(textFormat)
+	if err != nil {
+		return err
+	}
+
+	projection, err := client.Projection(cmd)
+	if err != nil {
+		return err
+	}
+
+	jiraMaxIssues, err := cmd.Flags().GetInt("jira-max-issues")
+	if err != nil {
+		return err
+	}
+
+	jiraClient, err := client.Jira(cmd)
+	if err != nil {
+		return err
+	}
+
+	board, err := client.Board(cmd, jiraClient)
+	if err != nil {
+		return err
+	}
+
+	report, err := jiraClient.FetchSprint(cmd.Context(), board.ID, sprint, "", fields, jiraMaxIssues)
+	if err != nil {
+		return err
+	}
+
+	report.Issues = jira.ConvertText(report.Issues, jiraTextFormat)
+	return output.WriteSprintReport(os.Stdout, format, report, projection)
+}
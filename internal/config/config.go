@@ -0,0 +1,174 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultProfile is the profile used when none is selected.
+const DefaultProfile = "default"
+
+// Config is the jp configuration file.
+type Config struct {
+	// Profile is the profile used when --profile is not set.
+	Profile  string             `yaml:"profile"`
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// Profile holds flag values keyed by flag name.
+type Profile map[string]any
+
+// Dir returns the jp configuration directory, following the XDG base directory specification.
+func Dir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "jp"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "jp"), nil
+}
+
+// Path returns the default path of the configuration file.
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// Load reads the configuration file at the given path,
+// a missing file is only an error if it is required.
+func Load(path string, required bool) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var config Config
+	if err = yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config file %s: %w", path, err)
+	}
+
+	return &config, nil
+}
+
+// Values returns the flag values of the given profile, or of the selected one if name is empty.
+func (c *Config) Values(name string) (map[string]string, error) {
+	explicit := name != ""
+	if !explicit {
+		name = c.Profile
+		explicit = name != ""
+	}
+	if !explicit {
+		name = DefaultProfile
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		if explicit {
+			return nil, fmt.Errorf("failed to find profile %q in config file", name)
+		}
+		return map[string]string{}, nil
+	}
+
+	values := make(map[string]string, len(profile))
+	for key, value := range profile {
+		str, err := toString(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %q in profile %q: %w", key, name, err)
+		}
+		values[key] = str
+	}
+
+	return values, nil
+}
+
+// toString converts a YAML value to its flag representation, lists being comma separated.
+func toString(value any) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			str, err := toString(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, str)
+		}
+		return strings.Join(items, ","), nil
+	case map[string]any:
+		return "", fmt.Errorf("unsupported value %v", v)
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+// EnvVar returns the environment variable overriding the given flag, e.g. JP_JIRA_TOKEN for jira-token.
+func EnvVar(flag string) string {
+	return "JP_" + strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// Unknown returns the profile keys and the environment variables prefixed with JP_ that match none of the known names,
+// such as a misspelt flag, which are otherwise ignored silently.
+func Unknown(values map[string]string, known func(name string) bool) []string {
+	var unknown []string
+	for key := range values {
+		if !known(key) {
+			unknown = append(unknown, fmt.Sprintf("profile key %q", key))
+		}
+	}
+
+	for _, env := range os.Environ() {
+		key, _, _ := strings.Cut(env, "=")
+		name, ok := strings.CutPrefix(key, "JP_")
+		if ok && !known(strings.ToLower(strings.ReplaceAll(name, "_", "-"))) {
+			unknown = append(unknown, "environment variable "+key)
+		}
+	}
+
+	slices.Sort(unknown)
+	return unknown
+}
+
+// Apply sets the flags that were not set on the command line from the environment,
+// or else from the profile values, leaving the default value otherwise.
+func Apply(flags *pflag.FlagSet, values map[string]string) error {
+	var errs []error
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Changed {
+			return
+		}
+
+		value, ok := os.LookupEnv(EnvVar(flag.Name))
+		if !ok {
+			value, ok = values[flag.Name]
+		}
+		if !ok {
+			return
+		}
+
+		if err := flags.Set(flag.Name, value); err != nil {
+			errs = append(errs, fmt.Errorf("failed to set %s: %w", flag.Name, err))
+		}
+	})
+	return errors.Join(errs...)
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeConfig writes the given YAML to a config file in a temporary directory.
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(content), 0o600)
+	assert.NoError(t, err, "Expected config file to be written")
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	path := writeConfig(t, `
+profile: forge
+profiles:
+  forge:
+    jira-url: https://forge.atlassian.net
+    jira-max-issues: 100
+    ollama-stream: false
+    jira-excluded-fields: [id, self, expand]
+  other:
+    jira-url: https://other.atlassian.net
+`)
+
+	config, err := Load(path, true)
+	assert.NoError(t, err, "Expected config file to be loaded")
+
+	// The selected profile is used when none is given
+	values, err := config.Values("")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"jira-url":             "https://forge.atlassian.net",
+		"jira-max-issues":      "100",
+		"ollama-stream":        "false",
+		"jira-excluded-fields": "id,self,expand",
+	}, values, "Expected values to be converted to their flag representation")
+
+	// A given profile takes precedence over the selected one
+	values, err = config.Values("other")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"jira-url": "https://other.atlassian.net"}, values)
+
+	// A missing profile is an error when it is explicitly requested
+	_, err = config.Values("missing")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `failed to find profile "missing"`)
+}
+
+func TestLoad_Missing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	// A missing default config file is equivalent to an empty one
+	config, err := Load(path, false)
+	assert.NoError(t, err, "Expected no error for a missing optional config file")
+
+	values, err := config.Values("")
+	assert.NoError(t, err, "Expected no error for a missing default profile")
+	assert.Empty(t, values)
+
+	// A missing config file given with --config is an error
+	_, err = Load(path, true)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read config file")
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	path := writeConfig(t, `profiles: [invalid`)
+
+	_, err := Load(path, true)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to unmarshal config file")
+}
+
+func TestEnvVar(t *testing.T) {
+	assert.Equal(t, "JP_JIRA_TOKEN", EnvVar("jira-token"))
+	assert.Equal(t, "JP_DEBUG", EnvVar("debug"))
+}
+
+// TestApply tests the precedence order flag > env > profile > default.
+func TestApply(t *testing.T) {
+	flags := pflag.NewFlagSet("jp", pflag.ContinueOnError)
+	flags.String("from-flag", "default", "")
+	flags.String("from-env", "default", "")
+	flags.String("from-profile", "default", "")
+	flags.String("from-default", "default", "")
+	flags.Int("invalid", 0, "")
+
+	err := flags.Parse([]string{"--from-flag", "flag"})
+	assert.NoError(t, err)
+
+	t.Setenv("JP_FROM_FLAG", "env")
+	t.Setenv("JP_FROM_ENV", "env")
+
+	err = Apply(flags, map[string]string{
+		"from-flag":    "profile",
+		"from-env":     "profile",
+		"from-profile": "profile",
+	})
+	assert.NoError(t, err)
+
+	for name, expected := range map[string]string{
+		"from-flag":    "flag",
+		"from-env":     "env",
+		"from-profile": "profile",
+		"from-default": "default",
+	} {
+		value, err := flags.GetString(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, value, "Unexpected value for %s", name)
+	}
+
+	// Values that cannot be parsed by the flag are reported
+	err = Apply(flags, map[string]string{"invalid": "not-a-number"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to set invalid")
+}
+
+func TestUnknown(t *testing.T) {
+	t.Setenv("JP_JIRA_TOKEN", "token")
+	t.Setenv("JP_JIRA_TOKNE", "token")
+
+	known := func(name string) bool {
+		return name == "jira-token" || name == "jql"
+	}
+	unknown := Unknown(map[string]string{"jql": "project = PROJ", "jqll": "project = PROJ"}, known)
+	assert.Equal(t, []string{`environment variable JP_JIRA_TOKNE`, `profile key "jqll"`}, unknown)
+}
@@ -0,0 +1,51 @@
+package credential
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Credential is the login to a Jira site.
+type Credential struct {
+	Email string `json:"email,omitempty"`
+	Token string `json:"token"`
+}
+
+// Store stores credentials per Jira url.
+type Store interface {
+	// Get returns the credential of the url, nil if there is none.
+	Get(url string) (*Credential, error)
+	// Set stores the credential of the url, replacing any previous one.
+	Set(url string, credential Credential) error
+	// Delete deletes the credential of the url, if any.
+	Delete(url string) error
+}
+
+// Open opens the store of the spec, either file, encrypted (with a passphrase) or helper:<command>,
+// the files of which are in the given dir.
+func Open(spec, dir, passphrase string) (Store, error) {
+	switch {
+	case spec == "file":
+		return NewFile(filepath.Join(dir, "credentials.json")), nil
+	case spec == "encrypted":
+		if passphrase == "" {
+			return nil, errors.New("a passphrase is required for the encrypted credential store, set it with JP_CREDENTIAL_PASSPHRASE")
+		}
+		return NewEncryptedFile(filepath.Join(dir, "credentials.enc"), passphrase), nil
+	case strings.HasPrefix(spec, "helper:"):
+		command := strings.TrimPrefix(spec, "helper:")
+		if command == "" {
+			return nil, errors.New("a command is required for the helper credential store, e.g. helper:osxkeychain")
+		}
+		return NewHelper(command), nil
+	default:
+		return nil, fmt.Errorf("unknown credential store %q, expected one of file, encrypted or helper:<command>", spec)
+	}
+}
+
+// key normalizes the url, so that it matches with or without a trailing slash.
+func key(url string) string {
+	return strings.TrimRight(url, "/")
+}
@@ -0,0 +1,146 @@
+package credential
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testStore tests getting, setting and deleting credentials of the store.
+func testStore(t *testing.T, store Store) {
+	const url = "https://example.atlassian.net"
+
+	credential, err := store.Get(url)
+	assert.NoError(t, err)
+	assert.Nil(t, credential, "Expected no credential before setting it")
+
+	assert.NoError(t, store.Set(url+"/", Credential{Email: "user@example.com", Token: "secret-token"}))
+	assert.NoError(t, store.Set("https://other.atlassian.net", Credential{Token: "other-token"}))
+
+	credential, err = store.Get(url)
+	assert.NoError(t, err)
+	assert.Equal(t, &Credential{Email: "user@example.com", Token: "secret-token"}, credential)
+
+	assert.NoError(t, store.Set(url, Credential{Email: "user@example.com", Token: "new-token"}))
+	credential, err = store.Get(url + "/")
+	assert.NoError(t, err)
+	assert.Equal(t, &Credential{Email: "user@example.com", Token: "new-token"}, credential)
+
+	assert.NoError(t, store.Delete(url))
+	credential, err = store.Get(url)
+	assert.NoError(t, err)
+	assert.Nil(t, credential, "Expected no credential after deleting it")
+
+	credential, err = store.Get("https://other.atlassian.net")
+	assert.NoError(t, err)
+	assert.Equal(t, &Credential{Token: "other-token"}, credential)
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jp", "credentials.json")
+	testStore(t, NewFile(path))
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "Credentials must only be readable by the user")
+}
+
+func TestEncryptedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	testStore(t, NewEncryptedFile(path, "passphrase"))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "other-token", "Credentials must be encrypted")
+
+	_, err = NewEncryptedFile(path, "wrong passphrase").Get("https://other.atlassian.net")
+	assert.EqualError(t, err, "failed to decrypt credentials, the passphrase may be wrong")
+}
+
+func TestHelper(t *testing.T) {
+	if _, err := os.Stat("/usr/bin/git"); err != nil {
+		t.Skip("git is required to test credential helpers")
+	}
+
+	path := filepath.Join(t.TempDir(), "git-credentials")
+	testStore(t, NewHelper("!git credential-store --file "+path))
+
+	// A helper name is found in the exec-path of git, which is not on the PATH
+	path = filepath.Join(t.TempDir(), "git-credentials")
+	testStore(t, NewHelper("store --file "+path))
+}
+
+func TestHelper_Error(t *testing.T) {
+	_, err := NewHelper("!exit 1 #").Get("https://example.atlassian.net")
+	assert.ErrorContains(t, err, `failed to run credential helper "exit 1 #"`)
+
+	err = NewHelper("!exit 1 #").Set("https://example.atlassian.net", Credential{Token: "token\nhost=evil.example.com"})
+	assert.EqualError(t, err, "credential password must not contain a newline or NUL character")
+
+	err = NewHelper("!exit 1 #").Set("https://example.atlassian.net", Credential{Email: "jane@example.com\x00", Token: "token"})
+	assert.EqualError(t, err, "credential username must not contain a newline or NUL character")
+}
+
+func TestNewHelper(t *testing.T) {
+	assert.Equal(t, "git credential-osxkeychain", NewHelper("osxkeychain").command)
+	assert.Equal(t, "/usr/local/bin/helper", NewHelper("/usr/local/bin/helper").command)
+	assert.Equal(t, "pass show jira", NewHelper("!pass show jira").command)
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+
+	testCases := []struct {
+		name          string
+		spec          string
+		passphrase    string
+		expected      Store
+		expectedError string
+	}{
+		{
+			name:     "file",
+			spec:     "file",
+			expected: NewFile(filepath.Join(dir, "credentials.json")),
+		},
+		{
+			name:       "encrypted",
+			spec:       "encrypted",
+			passphrase: "passphrase",
+			expected:   NewEncryptedFile(filepath.Join(dir, "credentials.enc"), "passphrase"),
+		},
+		{
+			name:          "encryptedWithoutPassphrase",
+			spec:          "encrypted",
+			expectedError: "a passphrase is required for the encrypted credential store, set it with JP_CREDENTIAL_PASSPHRASE",
+		},
+		{
+			name:     "helper",
+			spec:     "helper:libsecret",
+			expected: NewHelper("libsecret"),
+		},
+		{
+			name:          "helperWithoutCommand",
+			spec:          "helper:",
+			expectedError: "a command is required for the helper credential store, e.g. helper:osxkeychain",
+		},
+		{
+			name:          "unknown",
+			spec:          "keychain",
+			expectedError: `unknown credential store "keychain", expected one of file, encrypted or helper:<command>`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store, err := Open(tc.spec, dir, tc.passphrase)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, store)
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package credential
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// File stores credentials in a JSON file only readable by the user, encrypted if it has a passphrase.
+type File struct {
+	path       string
+	passphrase string
+}
+
+// Parameters of the encryption of the file, with AES-256-GCM and a key derived from the passphrase.
+const (
+	saltSize   = 16
+	keySize    = 32
+	iterations = 600_000
+)
+
+func NewFile(path string) *File {
+	return &File{path: path}
+}
+
+func NewEncryptedFile(path, passphrase string) *File {
+	return &File{path: path, passphrase: passphrase}
+}
+
+func (f *File) Get(url string) (*Credential, error) {
+	credentials, err := f.load()
+	if err != nil {
+		return nil, err
+	}
+
+	credential, ok := credentials[key(url)]
+	if !ok {
+		return nil, nil
+	}
+	return &credential, nil
+}
+
+func (f *File) Set(url string, credential Credential) error {
+	credentials, err := f.load()
+	if err != nil {
+		return err
+	}
+
+	credentials[key(url)] = credential
+	return f.save(credentials)
+}
+
+func (f *File) Delete(url string) error {
+	credentials, err := f.load()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := credentials[key(url)]; !ok {
+		return nil
+	}
+	delete(credentials, key(url))
+	return f.save(credentials)
+}
+
+func (f *File) load() (map[string]Credential, error) {
+	credentials := make(map[string]Credential)
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return credentials, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+
+	if f.passphrase != "" {
+		if data, err = decrypt(data, f.passphrase); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = json.Unmarshal(data, &credentials); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal credentials: %w", err)
+	}
+	return credentials, nil
+}
+
+func (f *File) save(credentials map[string]Credential) error {
+	data, err := json.MarshalIndent(credentials, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+
+	if f.passphrase != "" {
+		if data, err = encrypt(data, f.passphrase); err != nil {
+			return err
+		}
+	}
+
+	if err = os.MkdirAll(filepath.Dir(f.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create credentials directory: %w", err)
+	}
+
+	// The file is written aside and renamed, so that it is never left half written
+	tmp := f.path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	if err = os.Rename(tmp, f.path); err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	return nil
+}
+
+// encrypt seals the data as salt, nonce and ciphertext.
+func encrypt(data []byte, passphrase string) ([]byte, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to encrypt credentials: %w", err)
+	}
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to encrypt credentials: %w", err)
+	}
+
+	return gcm.Seal(append(salt, nonce...), nonce, data, nil), nil
+}
+
+// decrypt opens the data sealed by encrypt.
+func decrypt(data []byte, passphrase string) ([]byte, error) {
+	if len(data) < saltSize {
+		return nil, errors.New("failed to decrypt credentials: file is too short")
+	}
+	salt, data := data[:saltSize], data[saltSize:]
+
+	gcm, err := newGCM(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("failed to decrypt credentials: file is too short")
+	}
+	nonce, data := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, data, nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt credentials, the passphrase may be wrong")
+	}
+	return plaintext, nil
+}
+
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive credentials key: %w", err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create credentials cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create credentials cipher: %w", err)
+	}
+	return gcm, nil
+}
@@ -0,0 +1,109 @@
+package credential
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Helper stores credentials with an external command following the protocol of git credential helpers,
+// such as git-credential-osxkeychain, git-credential-libsecret or git-credential-manager for the OS keyring.
+type Helper struct {
+	command string
+}
+
+// tokenUsername is the username of credentials without email, as most helpers require one.
+const tokenUsername = "token"
+
+// NewHelper creates a store running the command as git does:
+// a shell command if prefixed with !, a path if absolute, or else the name of a git credential-<name> command,
+// which git finds in its exec-path even if not on the PATH.
+func NewHelper(command string) *Helper {
+	switch {
+	case strings.HasPrefix(command, "!"):
+		command = strings.TrimPrefix(command, "!")
+	case filepath.IsAbs(command):
+	default:
+		command = "git credential-" + command
+	}
+	return &Helper{command: command}
+}
+
+func (h *Helper) Get(url string) (*Credential, error) {
+	attributes, err := h.run("get", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if attributes["password"] == "" {
+		return nil, nil
+	}
+	credential := &Credential{Email: attributes["username"], Token: attributes["password"]}
+	if credential.Email == tokenUsername {
+		credential.Email = ""
+	}
+	return credential, nil
+}
+
+func (h *Helper) Set(url string, credential Credential) error {
+	_, err := h.run("store", url, &credential)
+	return err
+}
+
+func (h *Helper) Delete(url string) error {
+	_, err := h.run("erase", url, nil)
+	return err
+}
+
+// run runs the action of the helper with the attributes of the url and credential,
+// and returns the attributes it outputs.
+func (h *Helper) run(action, rawURL string, credential *Credential) (map[string]string, error) {
+	u, err := url.Parse(key(rawURL))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse url: %w", err)
+	}
+
+	attributes := [][2]string{{"protocol", u.Scheme}, {"host", u.Host}}
+	if path := strings.Trim(u.Path, "/"); path != "" {
+		attributes = append(attributes, [2]string{"path", path})
+	}
+	if credential != nil {
+		username := credential.Email
+		if username == "" {
+			username = tokenUsername
+		}
+		attributes = append(attributes, [2]string{"username", username}, [2]string{"password", credential.Token})
+	}
+
+	// A newline or NUL in a value would inject attributes into the protocol
+	var input bytes.Buffer
+	for _, attribute := range attributes {
+		if strings.ContainsAny(attribute[1], "\n\x00") {
+			return nil, fmt.Errorf("credential %s must not contain a newline or NUL character", attribute[0])
+		}
+		fmt.Fprintf(&input, "%s=%s\n", attribute[0], attribute[1])
+	}
+	input.WriteString("\n")
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("sh", "-c", h.command+" "+action)
+	cmd.Stdin = &input
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to run credential helper %q: %w: %s", h.command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	output := make(map[string]string)
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		if name, value, ok := strings.Cut(scanner.Text(), "="); ok {
+			output[name] = value
+		}
+	}
+	return output, nil
+}
@@ -0,0 +1,312 @@
+package jira
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ADFNode is a node of an Atlassian Document Format (ADF) document, as returned for rich text fields by the v3 API.
+type ADFNode struct {
+	Type    string         `json:"type"`
+	Version int            `json:"version,omitempty"`
+	Text    string         `json:"text,omitempty"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+	Marks   []ADFMark      `json:"marks,omitempty"`
+	Content []ADFNode      `json:"content,omitempty"`
+}
+
+// ADFMark is a formatting mark of an ADF text node, such as strong or link.
+type ADFMark struct {
+	Type  string         `json:"type"`
+	Attrs map[string]any `json:"attrs,omitempty"`
+}
+
+// ADFToMarkdown renders an ADF document as Markdown.
+func ADFToMarkdown(doc *ADFNode) string {
+	return adfRenderer{}.render(doc)
+}
+
+// ADFToText renders an ADF document as plain text.
+func ADFToText(doc *ADFNode) string {
+	return adfRenderer{plain: true}.render(doc)
+}
+
+type adfRenderer struct {
+	plain bool
+}
+
+func (r adfRenderer) render(doc *ADFNode) string {
+	if doc == nil {
+		return ""
+	}
+	return strings.TrimSpace(r.blocks(doc.Content))
+}
+
+// blocks renders block nodes separated by blank lines.
+func (r adfRenderer) blocks(nodes []ADFNode) string {
+	var blocks []string
+	for _, node := range nodes {
+		if block := r.block(node); strings.TrimSpace(block) != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+func (r adfRenderer) block(node ADFNode) string {
+	switch node.Type {
+	case "paragraph":
+		return r.inline(node.Content)
+	case "heading":
+		if r.plain {
+			return r.inline(node.Content)
+		}
+		level := max(1, min(6, attrInt(node.Attrs, "level")))
+		return strings.Repeat("#", level) + " " + r.inline(node.Content)
+	case "bulletList":
+		return r.list(node, func(int) string { return "- " })
+	case "orderedList":
+		start := max(1, attrInt(node.Attrs, "order"))
+		return r.list(node, func(i int) string { return strconv.Itoa(start+i) + ". " })
+	case "taskList":
+		return r.list(node, func(int) string { return "" })
+	case "decisionList":
+		return r.list(node, func(int) string { return "- " })
+	case "taskItem":
+		marker := "[ ] "
+		if attrString(node.Attrs, "state") == "DONE" {
+			marker = "[x] "
+		}
+		return "- " + marker + r.inline(node.Content)
+	case "codeBlock":
+		code := r.text(node.Content)
+		if r.plain {
+			return code
+		}
+		return "```" + attrString(node.Attrs, "language") + "\n" + code + "\n```"
+	case "blockquote":
+		return r.quote("", r.blocks(node.Content))
+	case "panel":
+		return r.quote(capitalize(attrString(node.Attrs, "panelType")), r.blocks(node.Content))
+	case "expand", "nestedExpand":
+		return r.quote(attrString(node.Attrs, "title"), r.blocks(node.Content))
+	case "rule":
+		if r.plain {
+			return ""
+		}
+		return "---"
+	case "table":
+		return r.table(node)
+	case "blockCard", "embedCard":
+		return attrString(node.Attrs, "url")
+	case "mediaSingle", "mediaGroup", "media":
+		return ""
+	default:
+		if len(node.Content) > 0 && node.Content[0].Type == "text" {
+			return r.inline(node.Content)
+		}
+		return r.blocks(node.Content)
+	}
+}
+
+// list renders the items of a list with their marker, indenting the nested blocks.
+func (r adfRenderer) list(node ADFNode, marker func(i int) string) string {
+	var items []string
+	for i, item := range node.Content {
+		var content string
+		if item.Type == "listItem" || item.Type == "decisionItem" {
+			var blocks []string
+			for _, child := range item.Content {
+				blocks = append(blocks, r.block(child))
+			}
+			content = strings.Join(blocks, "\n")
+		} else {
+			content = r.block(item)
+		}
+
+		prefix := marker(i)
+		indent := strings.Repeat(" ", len(prefix))
+		lines := strings.Split(content, "\n")
+		for j := range lines {
+			if j == 0 {
+				lines[j] = prefix + lines[j]
+			} else if lines[j] != "" {
+				lines[j] = indent + lines[j]
+			}
+		}
+		items = append(items, strings.Join(lines, "\n"))
+	}
+	return strings.Join(items, "\n")
+}
+
+// quote renders blocks as a Markdown quote, or as is in plain text, with an optional title.
+func (r adfRenderer) quote(title, content string) string {
+	if title != "" {
+		if r.plain {
+			content = title + ": " + content
+		} else {
+			content = "**" + title + "**\n" + content
+		}
+	}
+	if r.plain {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight("> "+line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r adfRenderer) table(node ADFNode) string {
+	var rows [][]string
+	for _, row := range node.Content {
+		var cells []string
+		for _, cell := range row.Content {
+			text := strings.ReplaceAll(r.blocks(cell.Content), "\n\n", " ")
+			text = strings.ReplaceAll(text, "\n", " ")
+			if !r.plain {
+				text = strings.ReplaceAll(text, "|", "\\|")
+			}
+			cells = append(cells, text)
+		}
+		rows = append(rows, cells)
+	}
+
+	var lines []string
+	for i, cells := range rows {
+		if r.plain {
+			lines = append(lines, strings.Join(cells, " | "))
+			continue
+		}
+
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+		// The first row is the header of Markdown tables
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", len(cells)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// inline renders inline nodes, such as text with marks, mentions and hard breaks.
+func (r adfRenderer) inline(nodes []ADFNode) string {
+	var sb strings.Builder
+	for _, node := range nodes {
+		switch node.Type {
+		case "text":
+			sb.WriteString(r.marks(node.Text, node.Marks))
+		case "hardBreak":
+			sb.WriteString("\n")
+		case "mention":
+			sb.WriteString("@" + strings.TrimPrefix(attrString(node.Attrs, "text"), "@"))
+		case "emoji":
+			if text := attrString(node.Attrs, "text"); text != "" {
+				sb.WriteString(text)
+			} else {
+				sb.WriteString(attrString(node.Attrs, "shortName"))
+			}
+		case "inlineCard":
+			sb.WriteString(attrString(node.Attrs, "url"))
+		case "status":
+			sb.WriteString("[" + attrString(node.Attrs, "text") + "]")
+		case "date":
+			sb.WriteString(adfDate(node.Attrs))
+		default:
+			sb.WriteString(r.inline(node.Content))
+		}
+	}
+	return sb.String()
+}
+
+func (r adfRenderer) marks(text string, marks []ADFMark) string {
+	if text == "" {
+		return ""
+	}
+
+	for _, mark := range marks {
+		switch mark.Type {
+		case "code":
+			if !r.plain {
+				text = "`" + text + "`"
+			}
+		case "strong":
+			if !r.plain {
+				text = "**" + text + "**"
+			}
+		case "em":
+			if !r.plain {
+				text = "*" + text + "*"
+			}
+		case "strike":
+			if !r.plain {
+				text = "~~" + text + "~~"
+			}
+		case "link":
+			href := attrString(mark.Attrs, "href")
+			switch {
+			case href == "" || href == text:
+			case r.plain:
+				text = fmt.Sprintf("%s (%s)", text, href)
+			default:
+				text = fmt.Sprintf("[%s](%s)", text, href)
+			}
+		}
+	}
+	return text
+}
+
+// text concatenates the text of the nodes, such as the code of a code block.
+func (r adfRenderer) text(nodes []ADFNode) string {
+	var sb strings.Builder
+	for _, node := range nodes {
+		sb.WriteString(node.Text)
+		sb.WriteString(r.text(node.Content))
+	}
+	return sb.String()
+}
+
+// adfDate formats the timestamp in milliseconds of a date node.
+func adfDate(attrs map[string]any) string {
+	timestamp := attrString(attrs, "timestamp")
+	ms, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return timestamp
+	}
+	return time.UnixMilli(ms).UTC().Format(time.DateOnly)
+}
+
+func attrString(attrs map[string]any, key string) string {
+	switch v := attrs[key].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	default:
+		return ""
+	}
+}
+
+func attrInt(attrs map[string]any, key string) int {
+	switch v := attrs[key].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
+
+// capitalize upper cases the first letter of s, e.g. the panel type info.
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,80 @@
+package jira
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const adfDocument = `{
+	"type": "doc",
+	"version": 1,
+	"content": [
+		{"type": "heading", "attrs": {"level": 2}, "content": [{"type": "text", "text": "Context"}]},
+		{"type": "paragraph", "content": [
+			{"type": "text", "text": "Ask "},
+			{"type": "mention", "attrs": {"id": "1", "text": "@Jane Doe"}},
+			{"type": "text", "text": " about the "},
+			{"type": "text", "text": "API", "marks": [{"type": "strong"}]},
+			{"type": "text", "text": ", see "},
+			{"type": "text", "text": "the docs", "marks": [{"type": "link", "attrs": {"href": "https://example.com"}}]},
+			{"type": "hardBreak"},
+			{"type": "text", "text": "run", "marks": [{"type": "code"}]},
+			{"type": "text", "text": " it "},
+			{"type": "status", "attrs": {"text": "BLOCKED"}}
+		]},
+		{"type": "bulletList", "content": [
+			{"type": "listItem", "content": [
+				{"type": "paragraph", "content": [{"type": "text", "text": "first"}]},
+				{"type": "orderedList", "attrs": {"order": 1}, "content": [
+					{"type": "listItem", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "nested", "marks": [{"type": "em"}]}]}]}
+				]}
+			]},
+			{"type": "listItem", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "second"}]}]}
+		]},
+		{"type": "codeBlock", "attrs": {"language": "go"}, "content": [{"type": "text", "text": "fmt.Println(\"hi\")"}]},
+		{"type": "panel", "attrs": {"panelType": "warning"}, "content": [
+			{"type": "paragraph", "content": [{"type": "text", "text": "Deploy on Monday"}]}
+		]},
+		{"type": "table", "content": [
+			{"type": "tableRow", "content": [
+				{"type": "tableHeader", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "Env"}]}]},
+				{"type": "tableHeader", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "Status"}]}]}
+			]},
+			{"type": "tableRow", "content": [
+				{"type": "tableCell", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "prod"}]}]},
+				{"type": "tableCell", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "up | running"}]}]}
+			]}
+		]},
+		{"type": "mediaSingle", "content": [{"type": "media", "attrs": {"id": "1", "type": "file"}}]}
+	]
+}`
+
+func newADFDocument(t *testing.T) *ADFNode {
+	var doc ADFNode
+	assert.NoError(t, json.Unmarshal([]byte(adfDocument), &doc))
+	return &doc
+}
+
+func TestADFToMarkdown(t *testing.T) {
+	assert.Equal(t, "## Context\n\n"+
+		"Ask @Jane Doe about the **API**, see [the docs](https://example.com)\n`run` it [BLOCKED]\n\n"+
+		"- first\n  1. *nested*\n- second\n\n"+
+		"```go\nfmt.Println(\"hi\")\n```\n\n"+
+		"> **Warning**\n> Deploy on Monday\n\n"+
+		"| Env | Status |\n| --- | --- |\n| prod | up \\| running |", ADFToMarkdown(newADFDocument(t)))
+}
+
+func TestADFToText(t *testing.T) {
+	assert.Equal(t, "Context\n\n"+
+		"Ask @Jane Doe about the API, see the docs (https://example.com)\nrun it [BLOCKED]\n\n"+
+		"- first\n  1. nested\n- second\n\n"+
+		"fmt.Println(\"hi\")\n\n"+
+		"Warning: Deploy on Monday\n\n"+
+		"Env | Status\nprod | up | running", ADFToText(newADFDocument(t)))
+}
+
+func TestADFToMarkdown_Nil(t *testing.T) {
+	assert.Equal(t, "", ADFToMarkdown(nil))
+}
@@ -0,0 +1,311 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// agilePageSize is the number of boards, sprints or issues fetched per Agile request.
+const agilePageSize = 50
+
+// Board is a Jira Agile board.
+type Board struct {
+	ID       int            `json:"id"`
+	Self     string         `json:"self,omitempty"`
+	Name     string         `json:"name"`
+	Type     string         `json:"type,omitempty"`
+	Location *BoardLocation `json:"location,omitempty"`
+}
+
+// BoardLocation is the project of a Jira Agile board.
+type BoardLocation struct {
+	ProjectID   int    `json:"projectId,omitempty"`
+	ProjectKey  string `json:"projectKey,omitempty"`
+	ProjectName string `json:"projectName,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+// Sprint is a sprint of a Jira Agile board.
+type Sprint struct {
+	ID            int    `json:"id"`
+	Self          string `json:"self,omitempty"`
+	State         string `json:"state"`
+	Name          string `json:"name"`
+	StartDate     string `json:"startDate,omitempty"`
+	EndDate       string `json:"endDate,omitempty"`
+	CompleteDate  string `json:"completeDate,omitempty"`
+	Goal          string `json:"goal,omitempty"`
+	OriginBoardID int    `json:"originBoardId,omitempty"`
+}
+
+// Sprint states.
+const (
+	SprintActive = "active"
+	SprintFuture = "future"
+	SprintClosed = "closed"
+)
+
+// SprintReport is a sprint along with its issues.
+type SprintReport struct {
+	Sprint Sprint  `json:"sprint"`
+	Issues []Issue `json:"issues"`
+}
+
+// Describe describes the sprint in a sentence, e.g. for a prompt.
+func (s Sprint) Describe() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Sprint %q is %s", s.Name, s.State)
+	if s.StartDate != "" && s.EndDate != "" {
+		fmt.Fprintf(&sb, ", from %s to %s", dateOnly(s.StartDate), dateOnly(s.EndDate))
+	}
+	if s.CompleteDate != "" {
+		fmt.Fprintf(&sb, ", completed on %s", dateOnly(s.CompleteDate))
+	}
+	if s.Goal != "" {
+		fmt.Fprintf(&sb, ", with the goal: %s", s.Goal)
+	}
+	return sb.String() + "."
+}
+
+// dateOnly returns the date of a timestamp such as 2024-01-31T09:30:00.000Z.
+func dateOnly(timestamp string) string {
+	date, _, _ := strings.Cut(timestamp, "T")
+	return date
+}
+
+// agilePage is a page of values of the Agile API.
+type agilePage[T any] struct {
+	StartAt    int  `json:"startAt"`
+	MaxResults int  `json:"maxResults"`
+	Total      int  `json:"total"`
+	IsLast     bool `json:"isLast"`
+	Values     []T  `json:"values"`
+}
+
+// Boards returns the boards whose name contains the given name, every board if empty.
+func (j *Jira) Boards(ctx context.Context, name string) ([]Board, error) {
+	query := map[string]string{}
+	if name != "" {
+		query["name"] = name
+	}
+
+	boards := make([]Board, 0)
+	for {
+		var page agilePage[Board]
+		if err := j.getAgile(ctx, "/rest/agile/1.0/board", query, len(boards), "boards", &page); err != nil {
+			return nil, err
+		}
+
+		boards = append(boards, page.Values...)
+		if page.IsLast || len(page.Values) == 0 {
+			break
+		}
+	}
+	return boards, nil
+}
+
+// Board returns the board with the given ID, or else the one with the given name.
+func (j *Jira) Board(ctx context.Context, idOrName string) (*Board, error) {
+	if id, err := strconv.Atoi(idOrName); err == nil {
+		var board Board
+		if err = j.getAgile(ctx, "/rest/agile/1.0/board/"+strconv.Itoa(id), nil, 0, "board", &board); err != nil {
+			return nil, err
+		}
+		return &board, nil
+	}
+
+	boards, err := j.Boards(ctx, idOrName)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, board := range boards {
+		if strings.EqualFold(board.Name, idOrName) {
+			return &board, nil
+		}
+		names = append(names, fmt.Sprintf("%q (%d)", board.Name, board.ID))
+	}
+
+	if len(boards) == 1 {
+		return &boards[0], nil
+	}
+	if len(boards) == 0 {
+		return nil, fmt.Errorf("no Jira board named %q", idOrName)
+	}
+	return nil, fmt.Errorf("several Jira boards match %q: %s", idOrName, strings.Join(names, ", "))
+}
+
+// Sprints returns the sprints of the board in the given states, every sprint if none.
+func (j *Jira) Sprints(ctx context.Context, boardID int, states ...string) ([]Sprint, error) {
+	query := map[string]string{}
+	if len(states) > 0 {
+		query["state"] = strings.Join(states, ",")
+	}
+
+	sprints := make([]Sprint, 0)
+	for {
+		var page agilePage[Sprint]
+		if err := j.getAgile(ctx, fmt.Sprintf("/rest/agile/1.0/board/%d/sprint", boardID), query, len(sprints), "sprints", &page); err != nil {
+			return nil, err
+		}
+
+		sprints = append(sprints, page.Values...)
+		if page.IsLast || len(page.Values) == 0 {
+			break
+		}
+	}
+	return sprints, nil
+}
+
+// Sprint returns the sprint with the given ID.
+func (j *Jira) Sprint(ctx context.Context, id int) (*Sprint, error) {
+	var sprint Sprint
+	if err := j.getAgile(ctx, fmt.Sprintf("/rest/agile/1.0/sprint/%d", id), nil, 0, "sprint", &sprint); err != nil {
+		return nil, err
+	}
+	return &sprint, nil
+}
+
+// ActiveSprint returns the active sprint of the board, failing if there are several of them.
+func (j *Jira) ActiveSprint(ctx context.Context, boardID int) (*Sprint, error) {
+	return j.stateSprint(ctx, boardID, SprintActive, SprintActive)
+}
+
+// stateSprint returns the sprint of the board in the given states, failing if there are none or several of them.
+func (j *Jira) stateSprint(ctx context.Context, boardID int, name string, states ...string) (*Sprint, error) {
+	sprints, err := j.Sprints(ctx, boardID, states...)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(sprints) {
+	case 0:
+		return nil, fmt.Errorf("no %s sprint on Jira board %d", name, boardID)
+	case 1:
+		return &sprints[0], nil
+	}
+
+	names := make([]string, len(sprints))
+	for i, sprint := range sprints {
+		names[i] = fmt.Sprintf("%q (%d)", sprint.Name, sprint.ID)
+	}
+	return nil, fmt.Errorf("several %s sprints on Jira board %d, select one by id or name: %s", name, boardID, strings.Join(names, ", "))
+}
+
+// FindSprint returns the sprint of the board: the only one in a state for active or current, open, future or closed,
+// as in JQL, or else the sprint of the board with the given ID or name.
+func (j *Jira) FindSprint(ctx context.Context, boardID int, sprint string) (*Sprint, error) {
+	switch state := strings.ToLower(sprint); state {
+	case SprintActive, "current":
+		return j.ActiveSprint(ctx, boardID)
+	case "open":
+		return j.stateSprint(ctx, boardID, state, SprintActive, SprintFuture)
+	case SprintFuture, SprintClosed:
+		return j.stateSprint(ctx, boardID, state, state)
+	}
+
+	sprints, err := j.Sprints(ctx, boardID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A sprint ID is looked up among the sprints of the board, so that a sprint of another board is not reported
+	id, err := strconv.Atoi(sprint)
+	if err == nil {
+		for _, s := range sprints {
+			if s.ID == id {
+				return &s, nil
+			}
+		}
+	}
+
+	for _, s := range sprints {
+		if strings.EqualFold(s.Name, sprint) {
+			return &s, nil
+		}
+	}
+
+	if err == nil {
+		return nil, fmt.Errorf("no sprint %d on Jira board %d", id, boardID)
+	}
+	return nil, fmt.Errorf("no sprint named %q on Jira board %d", sprint, boardID)
+}
+
+// FetchSprint returns the sprint of the board, as found by FindSprint, along with its issues matching the JQL, if any.
+func (j *Jira) FetchSprint(ctx context.Context, boardID int, sprint, jql string, fields []string, maxIssues int) (*SprintReport, error) {
+	found, err := j.FindSprint(ctx, boardID, sprint)
+	if err != nil {
+		return nil, err
+	}
+
+	issues, err := j.SprintIssues(ctx, found.ID, jql, fields, maxIssues)
+	if err != nil {
+		return nil, err
+	}
+	return &SprintReport{Sprint: *found, Issues: issues}, nil
+}
+
+// SprintIssues returns the issues of the sprint matching the JQL (all of them if empty)
+// with the given fields (all of them if empty), up to maxIssues issues (0 for no limit).
+func (j *Jira) SprintIssues(ctx context.Context, sprintID int, jql string, fields []string, maxIssues int) ([]Issue, error) {
+	zap.S().Infof("🏃 Fetching the issues of Jira sprint %d...", sprintID)
+	query := map[string]string{}
+	if jql != "" {
+		query["jql"] = jql
+	}
+	if len(fields) > 0 {
+		query["fields"] = strings.Join(fields, ",")
+	}
+
+	issues := make([]Issue, 0)
+	for {
+		var page SearchResponse
+		if err := j.getAgile(ctx, fmt.Sprintf("/rest/agile/1.0/sprint/%d/issue", sprintID), query, len(issues), "sprint issues", &page); err != nil {
+			return nil, err
+		}
+
+		issues = append(issues, page.Issues...)
+		if maxIssues > 0 && len(issues) >= maxIssues {
+			issues = issues[:maxIssues]
+			break
+		}
+
+		if len(page.Issues) == 0 || page.Total == nil || len(issues) >= *page.Total {
+			break
+		}
+	}
+
+	zap.S().Infof("✅ Fetched %d issues!", len(issues))
+	return issues, nil
+}
+
+// getAgile gets a resource of the Agile API, starting at the given index for paginated ones.
+func (j *Jira) getAgile(ctx context.Context, path string, query map[string]string, startAt int, name string, v any) error {
+	res, err := j.restClient.R().
+		SetContext(ctx).
+		SetQueryParams(query).
+		SetQueryParams(map[string]string{
+			"startAt":    strconv.Itoa(startAt),
+			"maxResults": strconv.Itoa(agilePageSize),
+		}).
+		Get(path)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode() != http.StatusOK {
+		return fmt.Errorf("failed to get Jira %s: %s", name, res.Status())
+	}
+
+	if err = json.Unmarshal(res.Body(), v); err != nil {
+		return fmt.Errorf("failed to unmarshal Jira %s: %w", name, err)
+	}
+	return nil
+}
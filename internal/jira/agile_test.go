@@ -0,0 +1,170 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newAgileServer creates a mock server of the boards "Team" (1) and "Team Ops" (2),
+// where board 1 has a closed sprint 10 and an active sprint 11 with three issues returned two per page,
+// one of which is assigned to the current user, and no future sprint,
+// and board 2 has the active sprints 20 and 21.
+func newAgileServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Basic test-auth-token", r.Header.Get("Authorization"))
+
+		query := r.URL.Query()
+		switch r.URL.Path {
+		case "/rest/agile/1.0/board":
+			switch query.Get("name") {
+			case "":
+				fmt.Fprint(w, `{"isLast": true, "values": [{"id": 1, "name": "Team"}, {"id": 2, "name": "Team Ops"}]}`)
+			case "ops":
+				fmt.Fprint(w, `{"isLast": true, "values": [{"id": 2, "name": "Team Ops"}]}`)
+			default:
+				fmt.Fprint(w, `{"isLast": true, "values": []}`)
+			}
+		case "/rest/agile/1.0/board/1":
+			fmt.Fprint(w, `{"id": 1, "name": "Team", "type": "scrum", "location": {"projectKey": "PROJ"}}`)
+		case "/rest/agile/1.0/board/1/sprint":
+			switch query.Get("state") {
+			case SprintActive, "active,future":
+				fmt.Fprint(w, `{"isLast": true, "values": [{"id": 11, "state": "active", "name": "Sprint 11"}]}`)
+			case SprintClosed:
+				fmt.Fprint(w, `{"isLast": true, "values": [{"id": 10, "state": "closed", "name": "Sprint 10"}]}`)
+			case SprintFuture:
+				fmt.Fprint(w, `{"isLast": true, "values": []}`)
+			default:
+				// The sprints are returned one per page
+				if query.Get("startAt") == "0" {
+					fmt.Fprint(w, `{"isLast": false, "values": [{"id": 10, "state": "closed", "name": "Sprint 10"}]}`)
+					return
+				}
+				fmt.Fprint(w, `{"isLast": true, "values": [{"id": 11, "state": "active", "name": "Sprint 11"}]}`)
+			}
+		case "/rest/agile/1.0/board/2/sprint":
+			fmt.Fprint(w, `{"isLast": true, "values": [{"id": 20, "state": "active", "name": "Ops 20"}, {"id": 21, "state": "active", "name": "Ops 21"}]}`)
+		case "/rest/agile/1.0/sprint/10":
+			fmt.Fprint(w, `{"id": 10, "state": "closed", "name": "Sprint 10"}`)
+		case "/rest/agile/1.0/sprint/11/issue":
+			assert.Equal(t, "summary,status", query.Get("fields"))
+			if query.Get("jql") == "assignee = currentUser()" {
+				fmt.Fprint(w, `{"total": 1, "issues": [{"key": "PROJ-2"}]}`)
+				return
+			}
+			if query.Get("startAt") == "0" {
+				fmt.Fprint(w, `{"total": 3, "issues": [{"key": "PROJ-1"}, {"key": "PROJ-2"}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"total": 3, "issues": [{"key": "PROJ-3"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestBoard(t *testing.T) {
+	mockServer := newAgileServer(t)
+	defer mockServer.Close()
+
+	j := New(mockServer.URL, "test-auth-token")
+
+	board, err := j.Board(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "PROJ", board.Location.ProjectKey)
+
+	board, err = j.Board(context.Background(), "ops")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, board.ID)
+
+	_, err = j.Board(context.Background(), "none")
+	assert.EqualError(t, err, `no Jira board named "none"`)
+
+	_, err = j.Board(context.Background(), "2")
+	assert.EqualError(t, err, "failed to get Jira board: 404 Not Found")
+}
+
+func TestBoards(t *testing.T) {
+	mockServer := newAgileServer(t)
+	defer mockServer.Close()
+
+	j := New(mockServer.URL, "test-auth-token")
+	boards, err := j.Boards(context.Background(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, []Board{{ID: 1, Name: "Team"}, {ID: 2, Name: "Team Ops"}}, boards)
+}
+
+func TestFindSprint(t *testing.T) {
+	mockServer := newAgileServer(t)
+	defer mockServer.Close()
+
+	j := New(mockServer.URL, "test-auth-token")
+
+	tests := []struct {
+		sprint string
+		id     int
+	}{
+		{sprint: "active", id: 11},
+		{sprint: "current", id: 11},
+		{sprint: "open", id: 11},
+		{sprint: "Closed", id: 10},
+		{sprint: "10", id: 10},
+		{sprint: "sprint 11", id: 11},
+	}
+	for _, test := range tests {
+		t.Run(test.sprint, func(t *testing.T) {
+			sprint, err := j.FindSprint(context.Background(), 1, test.sprint)
+			assert.NoError(t, err)
+			assert.Equal(t, test.id, sprint.ID)
+		})
+	}
+
+	_, err := j.FindSprint(context.Background(), 1, "Sprint 12")
+	assert.EqualError(t, err, `no sprint named "Sprint 12" on Jira board 1`)
+
+	_, err = j.FindSprint(context.Background(), 1, "20")
+	assert.EqualError(t, err, `no sprint 20 on Jira board 1`, "Expected the sprint ID to be looked up on the board")
+
+	_, err = j.FindSprint(context.Background(), 1, "future")
+	assert.EqualError(t, err, `no future sprint on Jira board 1`)
+
+	_, err = j.FindSprint(context.Background(), 2, "active")
+	assert.EqualError(t, err, `several active sprints on Jira board 2, select one by id or name: "Ops 20" (20), "Ops 21" (21)`)
+}
+
+func TestFetchSprint(t *testing.T) {
+	mockServer := newAgileServer(t)
+	defer mockServer.Close()
+
+	j := New(mockServer.URL, "test-auth-token")
+
+	report, err := j.FetchSprint(context.Background(), 1, "active", "", []string{"summary", "status"}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "Sprint 11", report.Sprint.Name)
+	assert.Equal(t, []Issue{{Key: "PROJ-1"}, {Key: "PROJ-2"}, {Key: "PROJ-3"}}, report.Issues)
+
+	report, err = j.FetchSprint(context.Background(), 1, "active", "", []string{"summary", "status"}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []Issue{{Key: "PROJ-1"}}, report.Issues)
+
+	report, err = j.FetchSprint(context.Background(), 1, "active", "assignee = currentUser()", []string{"summary", "status"}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []Issue{{Key: "PROJ-2"}}, report.Issues, "Expected the sprint issues to be filtered by the JQL")
+}
+
+func TestSprint_Describe(t *testing.T) {
+	sprint := Sprint{
+		Name:      "Sprint 11",
+		State:     SprintActive,
+		StartDate: "2024-01-15T09:00:00.000Z",
+		EndDate:   "2024-01-29T09:00:00.000Z",
+		Goal:      "Ship the search",
+	}
+	assert.Equal(t, `Sprint "Sprint 11" is active, from 2024-01-15 to 2024-01-29, with the goal: Ship the search.`, sprint.Describe())
+	assert.Equal(t, `Sprint "Sprint 12" is future.`, Sprint{Name: "Sprint 12", State: SprintFuture}.Describe())
+}
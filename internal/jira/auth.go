@@ -0,0 +1,162 @@
+package jira
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"go.uber.org/zap"
+)
+
+// DefaultOAuth2TokenURL is the token endpoint of Atlassian OAuth 2.0 (3LO) apps.
+const DefaultOAuth2TokenURL = "https://auth.atlassian.com/oauth/token"
+
+// Auth authenticates Jira requests.
+type Auth interface {
+	// Authorization returns the value of the Authorization header, none if empty.
+	Authorization(ctx context.Context) (string, error)
+}
+
+// BasicAuth authenticates with an email and an API token on Jira Cloud,
+// or with a token already encoded as base64 of email:token if there is no email.
+type BasicAuth struct {
+	Email, Token string
+}
+
+func (a BasicAuth) Authorization(context.Context) (string, error) {
+	if a.Token == "" {
+		return "", nil
+	}
+
+	if a.Email == "" {
+		return "Basic " + a.Token, nil
+	}
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(a.Email+":"+a.Token)), nil
+}
+
+// BearerAuth authenticates with a bearer token, such as a Personal Access Token on Jira Data Center.
+type BearerAuth struct {
+	Token string
+}
+
+func (a BearerAuth) Authorization(context.Context) (string, error) {
+	if a.Token == "" {
+		return "", nil
+	}
+	return "Bearer " + a.Token, nil
+}
+
+// OAuth2 authenticates with access tokens of an OAuth 2.0 app, granted with a refresh token if any,
+// or else with the client credentials, and granted again shortly before they expire.
+type OAuth2 struct {
+	restClient   *resty.Client
+	clientID     string
+	clientSecret string
+
+	mu           sync.Mutex
+	refreshToken string
+	accessToken  string
+	expiry       time.Time
+}
+
+// expiryLeeway is how long before its expiry an access token is granted again.
+const expiryLeeway = 30 * time.Second
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+	Error        string `json:"error"`
+	Description  string `json:"error_description"`
+}
+
+func NewOAuth2(tokenURL, clientID, clientSecret, refreshToken string) *OAuth2 {
+	return &OAuth2{
+		restClient:   resty.New().SetBaseURL(tokenURL).SetLogger(restyLogger{}),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		refreshToken: refreshToken,
+	}
+}
+
+func (a *OAuth2) Authorization(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.accessToken == "" || (!a.expiry.IsZero() && time.Now().Add(expiryLeeway).After(a.expiry)) {
+		if err := a.grant(ctx); err != nil {
+			return "", err
+		}
+	}
+	return "Bearer " + a.accessToken, nil
+}
+
+// grant requests a new access token, keeping the refresh token if rotated.
+func (a *OAuth2) grant(ctx context.Context) error {
+	body := map[string]string{
+		"grant_type":    "client_credentials",
+		"client_id":     a.clientID,
+		"client_secret": a.clientSecret,
+	}
+	if a.refreshToken != "" {
+		body["grant_type"] = "refresh_token"
+		body["refresh_token"] = a.refreshToken
+	}
+
+	zap.S().Debugf("🔑 Granting OAuth 2.0 access token with %s...", body["grant_type"])
+	var data tokenResponse
+	res, err := a.restClient.R().
+		SetContext(ctx).
+		SetFormData(body).
+		SetResult(&data).
+		SetError(&data).
+		Post("")
+	if err != nil {
+		return fmt.Errorf("failed to grant OAuth 2.0 access token: %w", err)
+	}
+
+	if res.StatusCode() != http.StatusOK {
+		if data.Error != "" {
+			return fmt.Errorf("failed to grant OAuth 2.0 access token: %s: %s %s", res.Status(), data.Error, data.Description)
+		}
+		return fmt.Errorf("failed to grant OAuth 2.0 access token: %s", res.Status())
+	}
+
+	if data.AccessToken == "" {
+		return fmt.Errorf("the \"access_token\" field is missing in the returned JSON: %s", res.Body())
+	}
+
+	a.accessToken = data.AccessToken
+	// An access token without expiry is kept
+	a.expiry = time.Time{}
+	if data.ExpiresIn > 0 {
+		a.expiry = time.Now().Add(time.Duration(data.ExpiresIn) * time.Second)
+	}
+	if data.RefreshToken != "" {
+		a.refreshToken = data.RefreshToken
+	}
+	return nil
+}
+
+// WithAuth authenticates the requests with the given auth instead of the token given to New.
+func (j *Jira) WithAuth(auth Auth) *Jira {
+	j.auth = auth
+	return j
+}
+
+// authenticate sets the Authorization header of each request attempt, so that expired tokens are granted again.
+func (j *Jira) authenticate(_ *resty.Client, r *resty.Request) error {
+	authorization, err := j.auth.Authorization(r.Context())
+	if err != nil {
+		return err
+	}
+
+	if authorization != "" {
+		r.SetHeader("Authorization", authorization)
+	}
+	return nil
+}
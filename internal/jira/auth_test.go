@@ -0,0 +1,183 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newAuthServer creates a mock Jira server recording the Authorization header of the last request.
+func newAuthServer(t *testing.T) (*httptest.Server, *atomic.Value) {
+	var authorization atomic.Value
+	authorization.Store("")
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization.Store(r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"issues":[],"isLast":true}`)
+	}))
+	t.Cleanup(mockServer.Close)
+	return mockServer, &authorization
+}
+
+func TestWithAuth(t *testing.T) {
+	testCases := []struct {
+		name     string
+		auth     Auth
+		expected string
+	}{
+		{
+			name:     "basicEncoded",
+			auth:     BasicAuth{Token: "dXNlckBleGFtcGxlLmNvbTp0b2tlbg=="},
+			expected: "Basic dXNlckBleGFtcGxlLmNvbTp0b2tlbg==",
+		},
+		{
+			name:     "basicEmail",
+			auth:     BasicAuth{Email: "user@example.com", Token: "token"},
+			expected: "Basic dXNlckBleGFtcGxlLmNvbTp0b2tlbg==",
+		},
+		{
+			name:     "basicNoToken",
+			auth:     BasicAuth{},
+			expected: "",
+		},
+		{
+			name:     "bearer",
+			auth:     BearerAuth{Token: "personal-access-token"},
+			expected: "Bearer personal-access-token",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServer, authorization := newAuthServer(t)
+
+			j := New(mockServer.URL, "").WithAuth(tc.auth)
+
+			_, err := j.SearchIssues(context.Background(), SearchRequest{JQL: "project=PROJ"})
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, authorization.Load())
+		})
+	}
+}
+
+func TestNew_Token(t *testing.T) {
+	mockServer, authorization := newAuthServer(t)
+
+	j := New(mockServer.URL, "test-auth-token")
+
+	_, err := j.SearchIssues(context.Background(), SearchRequest{JQL: "project=PROJ"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Basic test-auth-token", authorization.Load())
+}
+
+// newTokenServer creates a mock OAuth 2.0 token server granting numbered access tokens and rotating refresh tokens.
+func newTokenServer(t *testing.T, expiresIn int) (*httptest.Server, *atomic.Int32) {
+	var grants atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "client-id", r.PostForm.Get("client_id"))
+		assert.Equal(t, "client-secret", r.PostForm.Get("client_secret"))
+
+		w.Header().Set("Content-Type", "application/json")
+		grant := grants.Add(1)
+		switch r.PostForm.Get("grant_type") {
+		case "client_credentials":
+		case "refresh_token":
+			if r.PostForm.Get("refresh_token") != fmt.Sprintf("refresh-%d", grant-1) {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprintf(w, `{"error":"invalid_grant","error_description":"Unknown or invalid refresh token."}`)
+				return
+			}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		fmt.Fprintf(w, `{"access_token":"access-%d","refresh_token":"refresh-%d","expires_in":%d}`, grant, grant, expiresIn)
+	}))
+	t.Cleanup(mockServer.Close)
+	return mockServer, &grants
+}
+
+func TestOAuth2(t *testing.T) {
+	testCases := []struct {
+		name           string
+		refreshToken   string
+		expiresIn      int
+		expected       []string
+		expectedGrants int32
+	}{
+		{
+			name:           "clientCredentials",
+			expiresIn:      3600,
+			expected:       []string{"Bearer access-1", "Bearer access-1", "Bearer access-1"},
+			expectedGrants: 1,
+		},
+		{
+			name:           "refreshToken",
+			refreshToken:   "refresh-0",
+			expiresIn:      3600,
+			expected:       []string{"Bearer access-1", "Bearer access-1"},
+			expectedGrants: 1,
+		},
+		{
+			name:           "expiredRefreshToken",
+			refreshToken:   "refresh-0",
+			expiresIn:      1,
+			expected:       []string{"Bearer access-1", "Bearer access-2", "Bearer access-3"},
+			expectedGrants: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokenServer, grants := newTokenServer(t, tc.expiresIn)
+			mockServer, authorization := newAuthServer(t)
+
+			j := New(mockServer.URL, "").WithAuth(NewOAuth2(tokenServer.URL, "client-id", "client-secret", tc.refreshToken))
+
+			for _, expected := range tc.expected {
+				_, err := j.SearchIssues(context.Background(), SearchRequest{JQL: "project=PROJ"})
+				assert.NoError(t, err)
+				assert.Equal(t, expected, authorization.Load())
+			}
+			assert.Equal(t, tc.expectedGrants, grants.Load())
+		})
+	}
+}
+
+func TestOAuth2_InvalidGrant(t *testing.T) {
+	tokenServer, _ := newTokenServer(t, 3600)
+	mockServer, _ := newAuthServer(t)
+
+	j := New(mockServer.URL, "").WithAuth(NewOAuth2(tokenServer.URL, "client-id", "client-secret", "revoked"))
+
+	_, err := j.SearchIssues(context.Background(), SearchRequest{JQL: "project=PROJ"})
+	assert.ErrorContains(t, err, "failed to grant OAuth 2.0 access token: 403 Forbidden: invalid_grant Unknown or invalid refresh token.")
+}
+
+func TestMyself(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rest/api/2/myself", r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer personal-access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"accountId":"1","displayName":"Jane Doe","emailAddress":"jane@example.com","active":true}`)
+	}))
+	defer mockServer.Close()
+
+	user, err := New(mockServer.URL, "").WithAuth(BearerAuth{Token: "personal-access-token"}).Myself(context.Background())
+	assert.NoError(t, err)
+	active := true
+	assert.Equal(t, &User{AccountID: "1", DisplayName: "Jane Doe", EmailAddress: "jane@example.com", Active: &active}, user)
+
+	_, err = New(mockServer.URL, "").WithAuth(BearerAuth{Token: "revoked"}).Myself(context.Background())
+	assert.EqualError(t, err, "failed to get Jira user: 401 Unauthorized")
+}
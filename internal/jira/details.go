@@ -0,0 +1,219 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// DefaultConcurrency is the default number of issues whose details are fetched at once.
+const DefaultConcurrency = 4
+
+// detailsPageSize is the number of comments or worklogs fetched per request.
+const detailsPageSize = 100
+
+// Details are the details fetched for each issue on top of the search results.
+type Details struct {
+	Comments  bool
+	Changelog bool
+	Worklogs  bool
+	// Concurrency is the maximum number of issues whose details are fetched at once.
+	Concurrency int
+}
+
+// IsZero reports whether no detail is fetched.
+func (d Details) IsZero() bool {
+	return !d.Comments && !d.Changelog && !d.Worklogs
+}
+
+// FetchDetails fetches the details of the issues with a bounded pool of workers, merging them into each issue:
+// comments into fields.comment, worklogs into fields.worklog and the changelog into changelog.
+// Fetching stops at the first error.
+func (j *Jira) FetchDetails(ctx context.Context, issues []Issue, details Details) error {
+	if details.IsZero() || len(issues) == 0 {
+		return nil
+	}
+
+	zap.S().Infof("📎 Fetching details of %d Jira issues...", len(issues))
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range max(1, min(details.Concurrency, len(issues))) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := j.fetchIssueDetails(ctx, &issues[i], details); err != nil {
+					cancel(err)
+				}
+			}
+		}()
+	}
+
+send:
+	for i := range issues {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil {
+		return err
+	}
+
+	zap.S().Infof("✅ Details fetched!")
+	return nil
+}
+
+func (j *Jira) fetchIssueDetails(ctx context.Context, issue *Issue, details Details) error {
+	if details.Comments {
+		comments, err := j.Comments(ctx, issue.Key)
+		if err != nil {
+			return err
+		}
+		issue.Fields.Comment = comments
+	}
+
+	if details.Worklogs {
+		worklogs, err := j.Worklogs(ctx, issue.Key)
+		if err != nil {
+			return err
+		}
+		issue.Fields.Worklog = worklogs
+	}
+
+	if details.Changelog {
+		changelog, err := j.Changelog(ctx, issue.Key)
+		if err != nil {
+			return err
+		}
+		issue.Changelog = changelog
+	}
+	return nil
+}
+
+// Comments returns every comment of the issue.
+func (j *Jira) Comments(ctx context.Context, key string) (*Comments, error) {
+	comments := &Comments{Comments: make([]Comment, 0)}
+	for {
+		var page Comments
+		if err := j.getPage(ctx, j.api("/issue/{key}/comment"), key, len(comments.Comments), "comments", &page); err != nil {
+			return nil, err
+		}
+
+		comments.Comments = append(comments.Comments, page.Comments...)
+		if len(page.Comments) == 0 || len(comments.Comments) >= page.Total {
+			break
+		}
+	}
+
+	comments.Total = len(comments.Comments)
+	return comments, nil
+}
+
+// Worklogs returns every worklog of the issue.
+func (j *Jira) Worklogs(ctx context.Context, key string) (*Worklogs, error) {
+	worklogs := &Worklogs{Worklogs: make([]Worklog, 0)}
+	for {
+		var page Worklogs
+		if err := j.getPage(ctx, j.api("/issue/{key}/worklog"), key, len(worklogs.Worklogs), "worklogs", &page); err != nil {
+			return nil, err
+		}
+
+		worklogs.Worklogs = append(worklogs.Worklogs, page.Worklogs...)
+		if len(page.Worklogs) == 0 || len(worklogs.Worklogs) >= page.Total {
+			break
+		}
+	}
+
+	worklogs.Total = len(worklogs.Worklogs)
+	return worklogs, nil
+}
+
+// Changelog returns the changelog of the issue.
+// The histories expanded with the issue are only the first page of them, and the rest are paged through.
+func (j *Jira) Changelog(ctx context.Context, key string) (*Changelog, error) {
+	res, err := j.restClient.R().
+		SetContext(ctx).
+		SetPathParam("key", key).
+		SetQueryParams(map[string]string{
+			"expand": "changelog",
+			"fields": "summary",
+		}).
+		Get(j.api("/issue/{key}"))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("failed to get Jira changelog of %s: %s", key, res.Status())
+	}
+
+	var issue Issue
+	if err = json.Unmarshal(res.Body(), &issue); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Jira changelog of %s: %w", key, err)
+	}
+
+	changelog := issue.Changelog
+	if changelog == nil {
+		changelog = &Changelog{Histories: make([]History, 0)}
+	}
+
+	for len(changelog.Histories) < changelog.Total {
+		var page changelogPage
+		if err := j.getPage(ctx, j.api("/issue/{key}/changelog"), key, len(changelog.Histories), "changelog", &page); err != nil {
+			return nil, err
+		}
+
+		changelog.Histories = append(changelog.Histories, page.Values...)
+		if len(page.Values) == 0 || page.IsLast {
+			break
+		}
+	}
+
+	changelog.StartAt = 0
+	changelog.MaxResults = len(changelog.Histories)
+	changelog.Total = len(changelog.Histories)
+	return changelog, nil
+}
+
+// changelogPage is a page of the changelog of an issue, as paged through separately from the issue.
+type changelogPage struct {
+	IsLast bool      `json:"isLast"`
+	Values []History `json:"values"`
+}
+
+// getPage gets a page of the details of an issue starting at the given index.
+func (j *Jira) getPage(ctx context.Context, path, key string, startAt int, name string, page any) error {
+	res, err := j.restClient.R().
+		SetContext(ctx).
+		SetPathParam("key", key).
+		SetQueryParams(map[string]string{
+			"startAt":    strconv.Itoa(startAt),
+			"maxResults": strconv.Itoa(detailsPageSize),
+		}).
+		Get(path)
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode() != http.StatusOK {
+		return fmt.Errorf("failed to get Jira %s of %s: %s", name, key, res.Status())
+	}
+
+	if err = json.Unmarshal(res.Body(), page); err != nil {
+		return fmt.Errorf("failed to unmarshal Jira %s of %s: %w", name, key, err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newDetailsServer creates a mock server serving 3 comments in pages of 2, a worklog and 3 histories in pages of 2
+// for each issue, the first one being expanded with the issue,
+// recording the maximum number of concurrent requests.
+func newDetailsServer(t *testing.T, concurrent *atomic.Int32) *httptest.Server {
+	var inFlight atomic.Int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			peak := concurrent.Load()
+			if n <= peak || concurrent.CompareAndSwap(peak, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue/"), "/")
+		key := parts[0]
+		if key == "PROJ-404" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		switch {
+		case len(parts) == 1:
+			assert.Equal(t, "changelog", r.URL.Query().Get("expand"))
+			fmt.Fprintf(w, `{"key": %q, "changelog": {"startAt": 0, "maxResults": 1, "total": 3, "histories": [{"id": "1", "created": "2024-01-31T09:30:00.000+0100", "items": [{"field": "status", "fromString": "To Do", "toString": "In Progress"}]}]}}`, key)
+		case parts[1] == "comment":
+			startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+			comments := []string{
+				fmt.Sprintf(`{"id": "1", "body": "first comment of %s"}`, key),
+				`{"id": "2", "body": "second comment"}`,
+				`{"id": "3", "body": "third comment"}`,
+			}
+			fmt.Fprintf(w, `{"startAt": %d, "maxResults": 2, "total": 3, "comments": [%s]}`, startAt, strings.Join(comments[startAt:min(startAt+2, 3)], ","))
+		case parts[1] == "changelog":
+			startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+			histories := []string{`{"id": "1"}`, `{"id": "2"}`, `{"id": "3"}`}
+			end := min(startAt+2, 3)
+			fmt.Fprintf(w, `{"startAt": %d, "maxResults": 2, "total": 3, "isLast": %t, "values": [%s]}`, startAt, end == 3, strings.Join(histories[startAt:end], ","))
+		case parts[1] == "worklog":
+			fmt.Fprint(w, `{"startAt": 0, "maxResults": 100, "total": 1, "worklogs": [{"id": "1", "timeSpent": "1h", "timeSpentSeconds": 3600}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestFetchDetails(t *testing.T) {
+	var concurrent atomic.Int32
+	mockServer := newDetailsServer(t, &concurrent)
+	defer mockServer.Close()
+
+	issues := []Issue{{Key: "PROJ-1"}, {Key: "PROJ-2"}, {Key: "PROJ-3"}, {Key: "PROJ-4"}, {Key: "PROJ-5"}}
+
+	j := New(mockServer.URL, "test-auth-token")
+	err := j.FetchDetails(context.Background(), issues, Details{Comments: true, Changelog: true, Worklogs: true, Concurrency: 2})
+	assert.NoError(t, err)
+
+	for _, issue := range issues {
+		assert.Equal(t, 3, issue.Fields.Comment.Total)
+		assert.Len(t, issue.Fields.Comment.Comments, 3)
+		assert.Equal(t, Text{Markup: "first comment of " + issue.Key}, issue.Fields.Comment.Comments[0].Body)
+		assert.Equal(t, "third comment", issue.Fields.Comment.Comments[2].Body.Markup)
+
+		assert.Equal(t, []Worklog{{ID: "1", TimeSpent: "1h", TimeSpentSeconds: 3600}}, issue.Fields.Worklog.Worklogs)
+
+		assert.Equal(t, []ChangeItem{{Field: "status", FromString: "To Do", ToString: "In Progress"}}, issue.Changelog.Histories[0].Items)
+		assert.Equal(t, 3, issue.Changelog.Total)
+		assert.Len(t, issue.Changelog.Histories, 3, "Expected the histories beyond the expanded ones to be paged through")
+		assert.Equal(t, "3", issue.Changelog.Histories[2].ID)
+	}
+
+	assert.LessOrEqual(t, concurrent.Load(), int32(2), "Expected at most 2 concurrent requests")
+}
+
+func TestFetchDetails_Error(t *testing.T) {
+	var concurrent atomic.Int32
+	mockServer := newDetailsServer(t, &concurrent)
+	defer mockServer.Close()
+
+	issues := []Issue{{Key: "PROJ-1"}, {Key: "PROJ-404"}, {Key: "PROJ-3"}}
+
+	j := New(mockServer.URL, "test-auth-token").WithRetry(0, 0, 0)
+	err := j.FetchDetails(context.Background(), issues, Details{Comments: true, Concurrency: 2})
+	assert.EqualError(t, err, "failed to get Jira comments of PROJ-404: 404 Not Found")
+}
+
+func TestFetchDetails_None(t *testing.T) {
+	issues := []Issue{{Key: "PROJ-1"}}
+
+	j := New("http://127.0.0.1:0", "test-auth-token")
+	assert.NoError(t, j.FetchDetails(context.Background(), issues, Details{Concurrency: 2}))
+	assert.Nil(t, issues[0].Fields.Comment)
+}
@@ -0,0 +1,196 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Relation is a relation of a Jira issue expanded in an issue tree.
+type Relation string
+
+const (
+	// Subtasks are the subtasks of the issue.
+	Subtasks Relation = "subtasks"
+	// Links are the issues linked to the issue, e.g. the issues blocking it.
+	Links Relation = "links"
+	// Children are the issues whose parent is the issue, e.g. the issues of an epic.
+	Children Relation = "children"
+)
+
+// ParseRelations parses relation names.
+func ParseRelations(names []string) ([]Relation, error) {
+	relations := make([]Relation, 0, len(names))
+	for _, name := range names {
+		switch relation := Relation(strings.TrimSpace(name)); relation {
+		case Subtasks, Links, Children:
+			relations = append(relations, relation)
+		default:
+			return nil, fmt.Errorf("unknown relation %q, expected one of %s, %s or %s", name, Subtasks, Links, Children)
+		}
+	}
+	return relations, nil
+}
+
+// Tree is how an issue tree is fetched.
+type Tree struct {
+	// Depth is the number of levels of related issues fetched (0 for the issue only).
+	Depth int
+	// Relations are the relations of the issues expanded at each level.
+	Relations []Relation
+	// Fields are the fields returned for each issue (all of them if empty).
+	Fields []string
+}
+
+// Issue returns the issue with the given key.
+func (j *Jira) Issue(ctx context.Context, key string, fields []string) (*Issue, error) {
+	request := j.restClient.R().
+		SetContext(ctx).
+		SetPathParam("key", key)
+	if len(fields) > 0 {
+		request.SetQueryParam("fields", strings.Join(fields, ","))
+	}
+
+	res, err := request.Get(j.api("/issue/{key}"))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("failed to get Jira issue %s: %s", key, res.Status())
+	}
+
+	var issue Issue
+	if err = json.Unmarshal(res.Body(), &issue); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Jira issue %s: %w", key, err)
+	}
+	return &issue, nil
+}
+
+// IssueTree returns the issue with the given key, along with its related issues fetched down to the depth of the tree:
+// subtasks in fields.subtasks, linked issues in fields.issuelinks and children in children.
+// An issue already in the tree is not fetched again, so that links between issues do not loop.
+func (j *Jira) IssueTree(ctx context.Context, key string, tree Tree) (*Issue, error) {
+	zap.S().Infof("🌳 Fetching Jira issue %s...", key)
+	issue, err := j.Issue(ctx, key, tree.fields())
+	if err != nil {
+		return nil, err
+	}
+
+	visited := map[string]bool{issue.Key: true}
+	if err = j.expand(ctx, issue, tree, tree.Depth, visited); err != nil {
+		return nil, err
+	}
+
+	zap.S().Infof("✅ Fetched %d Jira issues!", len(visited))
+	return issue, nil
+}
+
+// fields returns the fields of the issues, with those needed to expand the relations.
+func (t Tree) fields() []string {
+	if len(t.Fields) == 0 {
+		return nil
+	}
+
+	fields := append([]string{}, t.Fields...)
+	for _, relation := range t.Relations {
+		switch relation {
+		case Subtasks:
+			fields = append(fields, "subtasks")
+		case Links:
+			fields = append(fields, "issuelinks")
+		}
+	}
+	return fields
+}
+
+func (t Tree) expands(relation Relation) bool {
+	return slices.Contains(t.Relations, relation)
+}
+
+// expand replaces the related issues of the issue with the fully fetched ones, down to the given depth.
+func (j *Jira) expand(ctx context.Context, issue *Issue, tree Tree, depth int, visited map[string]bool) error {
+	if depth <= 0 {
+		return nil
+	}
+
+	if tree.expands(Subtasks) {
+		for i, subtask := range issue.Fields.Subtasks {
+			related, err := j.related(ctx, subtask, tree, depth, visited)
+			if err != nil {
+				return err
+			}
+			issue.Fields.Subtasks[i] = *related
+		}
+	}
+
+	if tree.expands(Links) {
+		for i, link := range issue.Fields.IssueLinks {
+			for _, linked := range []**Issue{&link.InwardIssue, &link.OutwardIssue} {
+				if *linked == nil {
+					continue
+				}
+
+				related, err := j.related(ctx, **linked, tree, depth, visited)
+				if err != nil {
+					return err
+				}
+				*linked = related
+			}
+			issue.Fields.IssueLinks[i] = link
+		}
+	}
+
+	if tree.expands(Children) {
+		// Unlike getting an issue, searching returns no field unless asked for
+		fields := tree.fields()
+		if len(fields) == 0 {
+			fields = []string{"*all"}
+		}
+
+		res, err := j.SearchIssues(ctx, SearchRequest{
+			JQL:    fmt.Sprintf("parent = %s", Quote(issue.Key)),
+			Fields: fields,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, child := range res.Issues {
+			if visited[child.Key] {
+				continue
+			}
+			visited[child.Key] = true
+
+			if err = j.expand(ctx, &child, tree, depth-1, visited); err != nil {
+				return err
+			}
+			issue.Children = append(issue.Children, child)
+		}
+	}
+	return nil
+}
+
+// related fetches a related issue and expands it one level less deep,
+// unless it is already in the tree in which case it is left as returned with the issue.
+func (j *Jira) related(ctx context.Context, issue Issue, tree Tree, depth int, visited map[string]bool) (*Issue, error) {
+	if visited[issue.Key] {
+		return &issue, nil
+	}
+	visited[issue.Key] = true
+
+	related, err := j.Issue(ctx, issue.Key, tree.fields())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = j.expand(ctx, related, tree, depth-1, visited); err != nil {
+		return nil, err
+	}
+	return related, nil
+}
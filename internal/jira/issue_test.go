@@ -0,0 +1,119 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTreeServer creates a mock server of an epic PROJ-1 with a subtask PROJ-2 and a child PROJ-3,
+// where PROJ-3 is blocked by PROJ-4 which links back to PROJ-3.
+func newTreeServer(t *testing.T, fetched *[]string) *httptest.Server {
+	issues := map[string]string{
+		"PROJ-1": `{"key": "PROJ-1", "fields": {"summary": "Epic", "subtasks": [{"key": "PROJ-2", "fields": {"summary": "Subtask"}}]}}`,
+		"PROJ-2": `{"key": "PROJ-2", "fields": {"summary": "Subtask", "status": {"name": "Done"}}}`,
+		"PROJ-3": `{"key": "PROJ-3", "fields": {"summary": "Child", "issuelinks": [{"type": {"name": "Blocks", "inward": "is blocked by", "outward": "blocks"}, "inwardIssue": {"key": "PROJ-4"}}]}}`,
+		"PROJ-4": `{"key": "PROJ-4", "fields": {"summary": "Blocker", "issuelinks": [{"type": {"name": "Blocks"}, "outwardIssue": {"key": "PROJ-3"}}]}}`,
+	}
+	children := map[string]string{
+		"PROJ-1": issues["PROJ-3"],
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rest/api/2/search/jql" {
+			var request SearchRequest
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+
+			var parent string
+			_, err := fmt.Sscanf(request.JQL, "parent = %q", &parent)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"*all"}, request.Fields)
+
+			*fetched = append(*fetched, "parent="+parent)
+			fmt.Fprintf(w, `{"issues": [%s], "isLast": true}`, children[parent])
+			return
+		}
+
+		key := strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue/")
+		*fetched = append(*fetched, key)
+		issue, ok := issues[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, issue)
+	}))
+}
+
+func TestIssueTree(t *testing.T) {
+	var fetched []string
+	mockServer := newTreeServer(t, &fetched)
+	defer mockServer.Close()
+
+	j := New(mockServer.URL, "test-auth-token")
+	issue, err := j.IssueTree(context.Background(), "PROJ-1", Tree{Depth: 2, Relations: []Relation{Subtasks, Links, Children}})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Done", issue.Fields.Subtasks[0].Fields.Status.Name)
+	assert.Len(t, issue.Children, 1)
+	assert.Equal(t, "Child", issue.Children[0].Fields.Summary)
+
+	blocker := issue.Children[0].Fields.IssueLinks[0].InwardIssue
+	assert.Equal(t, "Blocker", blocker.Fields.Summary)
+	// The blocker is at the maximum depth, so its links are left as returned
+	assert.Equal(t, Issue{Key: "PROJ-3"}, *blocker.Fields.IssueLinks[0].OutwardIssue)
+
+	assert.Equal(t, []string{"PROJ-1", "PROJ-2", "parent=PROJ-2", "parent=PROJ-1", "PROJ-4", "parent=PROJ-3"}, fetched)
+}
+
+func TestIssueTree_Depth(t *testing.T) {
+	var fetched []string
+	mockServer := newTreeServer(t, &fetched)
+	defer mockServer.Close()
+
+	j := New(mockServer.URL, "test-auth-token")
+	issue, err := j.IssueTree(context.Background(), "PROJ-1", Tree{Depth: 0, Relations: []Relation{Subtasks, Links, Children}})
+	assert.NoError(t, err)
+	assert.Equal(t, Issue{Key: "PROJ-2", Fields: Fields{Summary: "Subtask"}}, issue.Fields.Subtasks[0])
+	assert.Empty(t, issue.Children)
+	assert.Equal(t, []string{"PROJ-1"}, fetched)
+}
+
+func TestIssueTree_Loop(t *testing.T) {
+	var fetched []string
+	mockServer := newTreeServer(t, &fetched)
+	defer mockServer.Close()
+
+	j := New(mockServer.URL, "test-auth-token")
+	issue, err := j.IssueTree(context.Background(), "PROJ-3", Tree{Depth: 5, Relations: []Relation{Links}})
+	assert.NoError(t, err)
+
+	// PROJ-3 is already in the tree, so it is not fetched again
+	assert.Equal(t, Issue{Key: "PROJ-3"}, *issue.Fields.IssueLinks[0].InwardIssue.Fields.IssueLinks[0].OutwardIssue)
+	assert.Equal(t, []string{"PROJ-3", "PROJ-4"}, fetched)
+}
+
+func TestIssue_NotFound(t *testing.T) {
+	var fetched []string
+	mockServer := newTreeServer(t, &fetched)
+	defer mockServer.Close()
+
+	j := New(mockServer.URL, "test-auth-token")
+	_, err := j.Issue(context.Background(), "PROJ-404", nil)
+	assert.EqualError(t, err, "failed to get Jira issue PROJ-404: 404 Not Found")
+}
+
+func TestParseRelations(t *testing.T) {
+	relations, err := ParseRelations([]string{"subtasks", "children"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Relation{Subtasks, Children}, relations)
+
+	_, err = ParseRelations([]string{"parents"})
+	assert.EqualError(t, err, `unknown relation "parents", expected one of subtasks, links or children`)
+}
@@ -1,10 +1,11 @@
 package jira
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strings"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"go.uber.org/zap"
@@ -12,51 +13,171 @@ import (
 
 type Jira struct {
 	restClient *resty.Client
+	auth       Auth
+	apiVersion int
 }
 
+// Versions of the Jira REST API: v2 returns rich text fields as wiki markup, and v3 as Atlassian Document Format.
+const (
+	APIv2 = 2
+	APIv3 = 3
+)
+
+// New creates a Jira client authenticating with a Basic token already encoded as base64 of email:token.
 func New(baseURL, authToken string) *Jira {
-	return &Jira{
-		restClient: resty.
-			New().
-			SetBaseURL(baseURL).
-			SetAuthScheme("Basic").
-			SetAuthToken(authToken).
-			SetHeader("Content-Type", "application/json"),
+	j := &Jira{auth: BasicAuth{Token: authToken}, apiVersion: APIv2}
+	j.restClient = resty.
+		New().
+		SetBaseURL(baseURL).
+		SetHeader("Content-Type", "application/json").
+		SetLogger(restyLogger{}).
+		OnBeforeRequest(j.authenticate).
+		AddRetryCondition(retryCondition).
+		AddRetryHook(retryHook).
+		SetRetryAfter(retryAfter)
+	return j.WithRetry(DefaultRetryCount, DefaultRetryWaitTime, DefaultRetryMaxWaitTime)
+}
+
+// WithTimeout bounds the time of each request (0 for no timeout).
+func (j *Jira) WithTimeout(timeout time.Duration) *Jira {
+	j.restClient.SetTimeout(timeout)
+	return j
+}
+
+// WithAPIVersion sets the version of the Jira REST API of the search, issue, comment, worklog and myself requests,
+// either APIv2 or APIv3.
+func (j *Jira) WithAPIVersion(version int) (*Jira, error) {
+	if version != APIv2 && version != APIv3 {
+		return nil, fmt.Errorf("unknown jira API version %d, expected %d or %d", version, APIv2, APIv3)
 	}
+	j.apiVersion = version
+	return j, nil
 }
 
-func (j *Jira) Search(body, excludedFields string) (string, error) {
-	zap.S().Infof("🔍 Searching Jira issues...")
-	res, err := j.restClient.R().
-		SetBody(body).
-		Post("/rest/api/2/search/jql")
+// api returns the path of a Jira REST API resource, e.g. /rest/api/2/myself.
+func (j *Jira) api(path string) string {
+	return fmt.Sprintf("/rest/api/%d%s", j.apiVersion, path)
+}
+
+// searchPageSize is the number of issues fetched per search request, unless set by the request, as by default in Jira.
+const searchPageSize = 50
+
+// Search searches Jira issues with the given JSON request body
+// and returns the JSON response without the excluded fields.
+func (j *Jira) Search(ctx context.Context, body, excludedFields string, maxIssues int) (string, error) {
+	request, err := ParseSearchRequest(body)
 	if err != nil {
 		return "", err
 	}
+	request.MaxIssues = maxIssues
 
-	if res.StatusCode() != http.StatusOK {
-		return "", fmt.Errorf("failed to search for Jira issues: %s", res.Status())
+	res, err := j.SearchIssues(ctx, request)
+	if err != nil {
+		return "", err
 	}
 
-	var data map[string]interface{}
-	if err = json.Unmarshal([]byte(res.String()), &data); err != nil {
-		return "", fmt.Errorf("failed to unmarshal Jira issues: %w", err)
+	return Encode(res, excludedFields)
+}
+
+// ParseSearchRequest parses a JSON search request body.
+func ParseSearchRequest(body string) (SearchRequest, error) {
+	var request SearchRequest
+	if err := json.Unmarshal([]byte(body), &request); err != nil {
+		return request, fmt.Errorf("failed to unmarshal Jira search request: %w", err)
 	}
+	return request, nil
+}
 
-	fieldsToRemove := make(map[string]bool)
-	for _, str := range strings.Split(excludedFields, ",") {
-		fieldsToRemove[str] = true
+// SearchIssues searches Jira issues following every page of results,
+// until the last one or until request.MaxIssues issues have been fetched.
+func (j *Jira) SearchIssues(ctx context.Context, request SearchRequest) (*SearchResponse, error) {
+	zap.S().Infof("🔍 Searching Jira issues...")
+	var data *SearchResponse
+	issues := make([]Issue, 0)
+	pageSize := request.MaxResults
+	if pageSize == 0 {
+		pageSize = searchPageSize
 	}
+	for page := 1; ; page++ {
+		// No more issues than are left to fetch are requested
+		if request.MaxIssues > 0 {
+			request.MaxResults = min(pageSize, request.MaxIssues-len(issues))
+		}
 
-	removeKeys(data, fieldsToRemove)
+		res, err := j.restClient.R().
+			SetContext(ctx).
+			SetBody(request).
+			Post(j.api("/search/jql"))
+		if err != nil {
+			return nil, err
+		}
 
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal Jira issues: %w", err)
+		if res.StatusCode() != http.StatusOK {
+			return nil, fmt.Errorf("failed to search for Jira issues: %s", res.Status())
+		}
+
+		var pageData SearchResponse
+		if err = json.Unmarshal(res.Body(), &pageData); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal Jira issues: %w", err)
+		}
+
+		issues = append(issues, pageData.Issues...)
+		zap.S().Infof("📄 Fetched page %d with %d issues (%d total)", page, len(pageData.Issues), len(issues))
+
+		if data == nil {
+			data = &pageData
+		}
+
+		if request.MaxIssues > 0 && len(issues) >= request.MaxIssues {
+			issues = issues[:request.MaxIssues]
+			break
+		}
+
+		// The last page either has isLast set or no token to fetch the next one
+		if pageData.IsLast || pageData.NextPageToken == "" {
+			break
+		}
+		request.NextPageToken = pageData.NextPageToken
 	}
 
+	// Every page is merged into a single response
+	data.Issues = issues
+	data.NextPageToken = ""
+	data.IsLast = false
+	delete(data.Extra, "nextPageToken")
+	delete(data.Extra, "isLast")
+
 	zap.S().Infof("✅ Search successful!")
-	return string(jsonData), nil
+	return data, nil
+}
+
+// Myself returns the user the requests are authenticated as.
+func (j *Jira) Myself(ctx context.Context) (*User, error) {
+	res, err := j.restClient.R().
+		SetContext(ctx).
+		Get(j.api("/myself"))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("failed to get Jira user: %s", res.Status())
+	}
+
+	var user User
+	if err = json.Unmarshal(res.Body(), &user); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Jira user: %w", err)
+	}
+	return &user, nil
+}
+
+// Encode marshals the given data to JSON without the excluded fields.
+func Encode(v any, excludedFields string) (string, error) {
+	projection, err := ParseProjection("", excludedFields)
+	if err != nil {
+		return "", err
+	}
+	return projection.Encode(v)
 }
 
 func removeKeys(data map[string]interface{}, unwantedKeys map[string]bool) {
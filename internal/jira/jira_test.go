@@ -1,10 +1,12 @@
 package jira
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -108,7 +110,7 @@ func TestSearch_Success(t *testing.T) {
 
 	// Call the Search method with a filter that removes "expand"
 	filters := "expand"
-	result, err := j.Search(`{"jql":"project=PROJ"}`, filters)
+	result, err := j.Search(context.Background(), `{"jql":"project=PROJ"}`, filters, 0)
 	assert.NoError(t, err, "Expected no error on successful response")
 
 	// Parse the resulting JSON
@@ -143,7 +145,7 @@ func TestSearch_NonOK(t *testing.T) {
 	j := New(mockServer.URL, "test-auth-token")
 
 	// Call the Search method, expecting an error
-	_, err := j.Search(`{"jql":"invalid"}`, "")
+	_, err := j.Search(context.Background(), `{"jql":"invalid"}`, "", 0)
 	assert.Error(t, err, "Expected an error for non-200 responses")
 	assert.Contains(t, err.Error(), "failed to search for Jira issues", "Error message should contain hint")
 }
@@ -162,7 +164,427 @@ func TestSearch_InvalidJSON(t *testing.T) {
 	j := New(mockServer.URL, "test-auth-token")
 
 	// Call the Search method, expecting an unmarshal error
-	_, err := j.Search(`{"jql":"project=PROJ"}`, "")
+	_, err := j.Search(context.Background(), `{"jql":"project=PROJ"}`, "", 0)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to unmarshal Jira issues", "Should return unmarshal error")
 }
+
+// newPaginatedServer creates a mock server serving the given pages of issue keys,
+// linking them together with nextPageToken.
+func newPaginatedServer(t *testing.T, pages [][]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&reqBody)
+		assert.NoError(t, err, "Expected to decode request body without error")
+		assert.Equal(t, "project=PROJ", reqBody["jql"], "jql should be preserved across pages")
+
+		// The page index is carried by the token, the first page has none
+		page := 0
+		if token, ok := reqBody["nextPageToken"].(string); ok {
+			_, err = fmt.Sscanf(token, "page-%d", &page)
+			assert.NoError(t, err, "Expected a token previously returned by the server")
+		}
+
+		issues := make([]map[string]interface{}, 0)
+		for _, key := range pages[page] {
+			issues = append(issues, map[string]interface{}{"key": key})
+		}
+
+		data := map[string]interface{}{
+			"issues": issues,
+			"isLast": page == len(pages)-1,
+		}
+		if page < len(pages)-1 {
+			data["nextPageToken"] = fmt.Sprintf("page-%d", page+1)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(data)
+	}))
+}
+
+// issueKeys extracts the issue keys from a Search result.
+func issueKeys(t *testing.T, result string) []string {
+	var resultData map[string]interface{}
+	err := json.Unmarshal([]byte(result), &resultData)
+	assert.NoError(t, err, "Result should be valid JSON")
+
+	issues, ok := resultData["issues"].([]interface{})
+	assert.True(t, ok, "Expected 'issues' key to be an array")
+
+	var keys []string
+	for _, issue := range issues {
+		keys = append(keys, issue.(map[string]interface{})["key"].(string))
+	}
+	return keys
+}
+
+// TestSearch_Pagination tests that Search follows nextPageToken until the last page.
+func TestSearch_Pagination(t *testing.T) {
+	mockServer := newPaginatedServer(t, [][]string{
+		{"PROJ-1", "PROJ-2"},
+		{"PROJ-3", "PROJ-4"},
+		{"PROJ-5"},
+	})
+	defer mockServer.Close()
+
+	j := New(mockServer.URL, "test-auth-token")
+
+	result, err := j.Search(context.Background(), `{"jql":"project=PROJ"}`, "", 0)
+	assert.NoError(t, err, "Expected no error on successful responses")
+
+	// All pages should be merged into a single issues array
+	assert.Equal(t, []string{"PROJ-1", "PROJ-2", "PROJ-3", "PROJ-4", "PROJ-5"}, issueKeys(t, result))
+
+	// Pagination details should not leak into the merged result
+	assert.NotContains(t, result, "nextPageToken", "Expected 'nextPageToken' to be removed")
+	assert.NotContains(t, result, "isLast", "Expected 'isLast' to be removed")
+}
+
+// TestSearch_MaxIssues tests that Search stops fetching pages once maxIssues is reached.
+func TestSearch_MaxIssues(t *testing.T) {
+	testCases := []struct {
+		name      string
+		maxIssues int
+		expected  []string
+	}{
+		{
+			name:      "capWithinFirstPage",
+			maxIssues: 1,
+			expected:  []string{"PROJ-1"},
+		},
+		{
+			name:      "capWithinSecondPage",
+			maxIssues: 3,
+			expected:  []string{"PROJ-1", "PROJ-2", "PROJ-3"},
+		},
+		{
+			name:      "capAboveTotal",
+			maxIssues: 10,
+			expected:  []string{"PROJ-1", "PROJ-2", "PROJ-3", "PROJ-4", "PROJ-5"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServer := newPaginatedServer(t, [][]string{
+				{"PROJ-1", "PROJ-2"},
+				{"PROJ-3", "PROJ-4"},
+				{"PROJ-5"},
+			})
+			defer mockServer.Close()
+
+			j := New(mockServer.URL, "test-auth-token")
+
+			result, err := j.Search(context.Background(), `{"jql":"project=PROJ"}`, "", tc.maxIssues)
+			assert.NoError(t, err, "Expected no error on successful responses")
+			assert.Equal(t, tc.expected, issueKeys(t, result))
+		})
+	}
+}
+
+// TestSearchIssues_MaxResults tests that SearchIssues requests no more issues per page than are left to fetch.
+func TestSearchIssues_MaxResults(t *testing.T) {
+	testCases := []struct {
+		name       string
+		maxResults int
+		maxIssues  int
+		expected   []any
+	}{
+		{
+			name:      "defaultPageSize",
+			maxIssues: 3,
+			expected:  []any{3.0},
+		},
+		{
+			name:       "requestPageSize",
+			maxResults: 2,
+			maxIssues:  5,
+			expected:   []any{2.0, 2.0, 1.0},
+		},
+		{
+			name:     "noLimit",
+			expected: []any{nil},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requested []any
+			mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var reqBody map[string]interface{}
+				err := json.NewDecoder(r.Body).Decode(&reqBody)
+				assert.NoError(t, err, "Expected to decode request body without error")
+				requested = append(requested, reqBody["maxResults"])
+
+				// Each page holds as many issues as requested, over an unlimited number of pages
+				size := 1
+				if maxResults, ok := reqBody["maxResults"].(float64); ok {
+					size = int(maxResults)
+				}
+				issues := make([]map[string]interface{}, 0, size)
+				for range size {
+					issues = append(issues, map[string]interface{}{"key": fmt.Sprintf("PROJ-%d", len(requested))})
+				}
+
+				w.WriteHeader(http.StatusOK)
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"issues": issues, "nextPageToken": "next", "isLast": tc.maxIssues == 0})
+			}))
+			defer mockServer.Close()
+
+			j := New(mockServer.URL, "test-auth-token")
+
+			res, err := j.SearchIssues(context.Background(), SearchRequest{JQL: "project=PROJ", MaxResults: tc.maxResults, MaxIssues: tc.maxIssues})
+			assert.NoError(t, err, "Expected no error on successful responses")
+			assert.Equal(t, tc.expected, requested, "Unexpected maxResults of the requests")
+			if tc.maxIssues > 0 {
+				assert.Len(t, res.Issues, tc.maxIssues)
+			}
+		})
+	}
+}
+
+// TestSearch_InvalidRequest tests that Search rejects a malformed request body before calling Jira.
+func TestSearch_InvalidRequest(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+		t.Fatal("Jira should not be called with an invalid request")
+	}))
+	defer mockServer.Close()
+
+	j := New(mockServer.URL, "test-auth-token")
+
+	_, err := j.Search(context.Background(), `invalid-json`, "", 0)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to unmarshal Jira search request", "Should return request unmarshal error")
+}
+
+// TestSearchIssues tests that SearchIssues decodes the response into typed issues.
+func TestSearchIssues(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Check that the typed request is sent as is
+		var reqBody map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&reqBody)
+		assert.NoError(t, err, "Expected to decode request body without error")
+		assert.Equal(t, "project=PROJ", reqBody["jql"])
+		assert.Equal(t, []interface{}{"summary", "status", "assignee", "customfield_10020"}, reqBody["fields"])
+		assert.NotContains(t, reqBody, "maxIssues", "Expected MaxIssues not to be sent to Jira")
+
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{
+            "issues": [
+                {
+                    "id": "10000",
+                    "key": "PROJ-1",
+                    "fields": {
+                        "summary": "Issue summary",
+                        "status": {"name": "In Progress", "statusCategory": {"key": "indeterminate"}},
+                        "assignee": {"accountId": "abc", "displayName": "Jane Doe"},
+                        "customfield_10020": [{"name": "Sprint 1"}]
+                    }
+                },
+                {
+                    "id": "10001",
+                    "key": "PROJ-2",
+                    "fields": {
+                        "summary": "Unassigned issue",
+                        "assignee": null
+                    }
+                }
+            ],
+            "isLast": true
+        }`)
+	}))
+	defer mockServer.Close()
+
+	j := New(mockServer.URL, "test-auth-token")
+
+	res, err := j.SearchIssues(context.Background(), SearchRequest{
+		JQL:       "project=PROJ",
+		Fields:    []string{"summary", "status", "assignee", "customfield_10020"},
+		MaxIssues: 10,
+	})
+	assert.NoError(t, err, "Expected no error on successful response")
+	assert.Len(t, res.Issues, 2, "Expected 2 issues")
+
+	// Known fields are typed
+	issue := res.Issues[0]
+	assert.Equal(t, "PROJ-1", issue.Key)
+	assert.Equal(t, "Issue summary", issue.Fields.Summary)
+	assert.Equal(t, "In Progress", issue.Fields.Status.Name)
+	assert.Equal(t, "indeterminate", issue.Fields.Status.StatusCategory.Key)
+	assert.Equal(t, "Jane Doe", issue.Fields.Assignee.DisplayName)
+	assert.Nil(t, res.Issues[1].Fields.Assignee, "Expected a null assignee to be nil")
+
+	// Unknown fields end up in the custom field bag
+	var sprints []map[string]string
+	ok, err := issue.Fields.CustomField("customfield_10020", &sprints)
+	assert.True(t, ok, "Expected custom field to be present")
+	assert.NoError(t, err)
+	assert.Equal(t, "Sprint 1", sprints[0]["name"])
+
+	ok, err = issue.Fields.CustomField("customfield_99999", &sprints)
+	assert.False(t, ok, "Expected missing custom field to be reported")
+	assert.NoError(t, err)
+}
+
+// TestSearchIssues_APIv3 tests that the v3 API is searched and that its ADF rich text fields get converted.
+func TestSearchIssues_APIv3(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rest/api/3/search/jql", r.URL.Path)
+
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{
+            "issues": [
+                {
+                    "key": "PROJ-1",
+                    "fields": {
+                        "summary": "Issue summary",
+                        "description": {
+                            "type": "doc",
+                            "version": 1,
+                            "content": [
+                                {"type": "heading", "attrs": {"level": 1}, "content": [{"type": "text", "text": "Title"}]},
+                                {"type": "paragraph", "content": [{"type": "text", "text": "Fails", "marks": [{"type": "strong"}]}]}
+                            ]
+                        }
+                    }
+                }
+            ],
+            "isLast": true
+        }`)
+	}))
+	defer mockServer.Close()
+
+	_, err := New(mockServer.URL, "test-auth-token").WithAPIVersion(4)
+	assert.Error(t, err, "Expected an unknown API version to fail")
+
+	j, err := New(mockServer.URL, "test-auth-token").WithAPIVersion(APIv3)
+	assert.NoError(t, err)
+
+	res, err := j.SearchIssues(context.Background(), SearchRequest{JQL: "project=PROJ"})
+	assert.NoError(t, err, "Expected no error on successful response")
+	assert.Len(t, res.Issues, 1, "Expected 1 issue")
+
+	converted := ConvertText(res.Issues, TextMarkdown)
+	assert.Equal(t, Text{Markup: "# Title\n\n**Fails**"}, converted[0].Fields.Description)
+
+	converted = ConvertText(res.Issues, TextPlain)
+	assert.Equal(t, Text{Markup: "Title\n\nFails"}, converted[0].Fields.Description)
+}
+
+// TestFields_RoundTrip tests that custom fields survive marshalling the typed fields back to JSON.
+func TestFields_RoundTrip(t *testing.T) {
+	input := `{"customfield_10020":[{"name":"Sprint 1"}],"labels":["backend"],"status":{"name":"Done"},"summary":"Issue summary"}`
+
+	var fields Fields
+	err := json.Unmarshal([]byte(input), &fields)
+	assert.NoError(t, err)
+	assert.Len(t, fields.Custom, 1, "Expected only unknown fields in the custom field bag")
+
+	output, err := json.Marshal(fields)
+	assert.NoError(t, err)
+	assert.JSONEq(t, input, string(output), "Expected known and custom fields to be marshalled back")
+}
+
+// TestSearch_Unchanged tests that the typed issues marshal back every key returned by Jira,
+// so that the search output is the JSON response without the excluded fields, as it always was.
+func TestSearch_Unchanged(t *testing.T) {
+	response := `{
+        "expand": "schema,names",
+        "startAt": 0,
+        "maxResults": 50,
+        "total": 1,
+        "names": {"summary": "Summary", "customfield_10020": "Sprint"},
+        "schema": {"summary": {"type": "string", "system": "summary"}},
+        "issues": [
+            {
+                "expand": "renderedFields",
+                "id": "10000",
+                "self": "https://example.atlassian.net/rest/api/2/issue/10000",
+                "key": "PROJ-1",
+                "renderedFields": {"description": "<p>Fails</p>"},
+                "fields": {
+                    "summary": "Issue summary",
+                    "description": null,
+                    "labels": [],
+                    "duedate": null,
+                    "reporter": null,
+                    "resolution": null,
+                    "customfield_10020": [{"id": 1, "name": "Sprint 1"}],
+                    "issuetype": {"id": "1", "name": "Bug", "subtask": false, "avatarId": 10303, "iconUrl": "https://example.atlassian.net/bug.svg"},
+                    "status": {
+                        "name": "In Progress",
+                        "iconUrl": "https://example.atlassian.net/status.png",
+                        "statusCategory": {"id": 4, "key": "indeterminate", "colorName": "yellow", "name": "In Progress"}
+                    },
+                    "priority": {"id": "3", "name": "Medium", "iconUrl": "https://example.atlassian.net/medium.svg"},
+                    "assignee": {
+                        "accountId": "abc",
+                        "accountType": "atlassian",
+                        "displayName": "Jane Doe",
+                        "active": false,
+                        "timeZone": "Europe/Berlin",
+                        "avatarUrls": {"48x48": "https://example.atlassian.net/avatar.png"}
+                    },
+                    "fixVersions": [{"id": "1", "name": "1.0", "released": false, "archived": false}],
+                    "comment": {"comments": [], "maxResults": 0, "total": 0, "startAt": 0},
+                    "issuelinks": [
+                        {
+                            "id": "1",
+                            "type": {"id": "1", "name": "Blocks", "inward": "is blocked by", "outward": "blocks"},
+                            "outwardIssue": {"id": "10001", "key": "PROJ-2", "fields": {"summary": "Blocked", "status": {"name": "To Do", "iconUrl": "https://example.atlassian.net/todo.png"}}}
+                        }
+                    ]
+                }
+            }
+        ]
+    }`
+
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, response)
+	}))
+	defer mockServer.Close()
+
+	j := New(mockServer.URL, "test-auth-token")
+
+	for _, excludedFields := range []string{"", "id,self,expand"} {
+		result, err := j.Search(context.Background(), `{"jql":"project=PROJ"}`, excludedFields, 0)
+		assert.NoError(t, err, "Expected no error on successful response")
+
+		// The output of the untyped search, without the excluded fields
+		var expected map[string]interface{}
+		err = json.Unmarshal([]byte(response), &expected)
+		assert.NoError(t, err)
+		unwantedKeys := make(map[string]bool)
+		for _, key := range strings.Split(excludedFields, ",") {
+			unwantedKeys[key] = true
+		}
+		removeKeys(expected, unwantedKeys)
+
+		data, err := json.Marshal(expected)
+		assert.NoError(t, err)
+		assert.JSONEq(t, string(data), result, "Expected every key returned by Jira to be kept")
+	}
+}
+
+// TestSearchIssues_Canceled tests that SearchIssues stops following pages once its context is canceled.
+func TestSearchIssues_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Mock server cancels the context while serving the first page
+	requests := 0
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		cancel()
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"issues":[{"key":"PROJ-1"}],"nextPageToken":"next"}`)
+	}))
+	defer mockServer.Close()
+
+	j := New(mockServer.URL, "test-auth-token")
+
+	_, err := j.SearchIssues(ctx, SearchRequest{JQL: "project=PROJ"})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.LessOrEqual(t, requests, 1, "Expected no page to be fetched after cancellation")
+}
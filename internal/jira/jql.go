@@ -0,0 +1,163 @@
+package jira
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query composes the JQL of a search request from high-level criteria.
+type Query struct {
+	// JQL is an additional raw JQL query, its ORDER BY clause being kept last.
+	JQL string
+	// Projects are the keys or names of the projects of the issues.
+	Projects []string
+	// Statuses are the names of the statuses of the issues.
+	Statuses []string
+	// Assignee is the assignee of the issues: me, unassigned, or an account ID or name.
+	Assignee string
+	// Sprint is the sprint of the issues: current, future, closed, or a sprint ID or name.
+	Sprint string
+	// UpdatedSince is how long ago the issues were last updated, e.g. 7d, or a date such as 2024-01-31.
+	UpdatedSince string
+	// Fields are the fields returned for each issue.
+	Fields []string
+}
+
+// IsZero reports whether the query has no JQL criteria.
+func (q Query) IsZero() bool {
+	return q.JQL == "" && len(q.Projects) == 0 && len(q.Statuses) == 0 &&
+		q.Assignee == "" && q.Sprint == "" && q.UpdatedSince == ""
+}
+
+var (
+	orderBy      = regexp.MustCompile(`(?i)\s*\border\s+by\b`)
+	relativeDate = regexp.MustCompile(`^\d+[mhdw]$`)
+	// The open sprints include the future ones, unlike the current or active sprints
+	sprintClauses = map[string]string{
+		"current": "sprint in openSprints() AND sprint not in futureSprints()",
+		"active":  "sprint in openSprints() AND sprint not in futureSprints()",
+		"open":    "sprint in openSprints()",
+		"future":  "sprint in futureSprints()",
+		"closed":  "sprint in closedSprints()",
+	}
+)
+
+// SearchRequest composes the search request of the query, the criteria being joined with AND.
+func (q Query) SearchRequest() (SearchRequest, error) {
+	var clauses []string
+	var order string
+
+	if jql := strings.TrimSpace(q.JQL); jql != "" {
+		if loc := orderBy.FindStringIndex(jql); loc != nil {
+			jql, order = strings.TrimSpace(jql[:loc[0]]), strings.TrimSpace(jql[loc[0]:])
+		}
+		if jql != "" {
+			clauses = append(clauses, "("+jql+")")
+		}
+	}
+
+	for _, criterion := range []struct {
+		field  string
+		values []string
+	}{
+		{field: "project", values: q.Projects},
+		{field: "status", values: q.Statuses},
+	} {
+		clause, err := inClause(criterion.field, criterion.values)
+		if err != nil {
+			return SearchRequest{}, err
+		}
+		if clause != "" {
+			clauses = append(clauses, clause)
+		}
+	}
+
+	if q.Assignee != "" {
+		clauses = append(clauses, assigneeClause(q.Assignee))
+	}
+
+	if q.Sprint != "" {
+		clauses = append(clauses, sprintClause(q.Sprint))
+	}
+
+	if q.UpdatedSince != "" {
+		clause, err := updatedClause(q.UpdatedSince)
+		if err != nil {
+			return SearchRequest{}, err
+		}
+		clauses = append(clauses, clause)
+	}
+
+	if len(clauses) == 0 {
+		return SearchRequest{}, errors.New("jira query requires at least one criterion")
+	}
+
+	jql := strings.Join(clauses, " AND ")
+	if order != "" {
+		jql += " " + order
+	}
+
+	return SearchRequest{
+		JQL:    jql,
+		Fields: q.Fields,
+	}, nil
+}
+
+func inClause(field string, values []string) (string, error) {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value == "" {
+			return "", fmt.Errorf("jira %s must not be empty", field)
+		}
+		quoted = append(quoted, Quote(value))
+	}
+
+	switch len(quoted) {
+	case 0:
+		return "", nil
+	case 1:
+		return fmt.Sprintf("%s = %s", field, quoted[0]), nil
+	default:
+		return fmt.Sprintf("%s in (%s)", field, strings.Join(quoted, ", ")), nil
+	}
+}
+
+func assigneeClause(assignee string) string {
+	switch strings.ToLower(assignee) {
+	case "me", "currentuser()":
+		return "assignee = currentUser()"
+	case "unassigned", "none":
+		return "assignee is EMPTY"
+	default:
+		return "assignee = " + Quote(assignee)
+	}
+}
+
+func sprintClause(sprint string) string {
+	if clause, ok := sprintClauses[strings.ToLower(sprint)]; ok {
+		return clause
+	}
+	if _, err := strconv.Atoi(sprint); err == nil {
+		return "sprint = " + sprint
+	}
+	return "sprint = " + Quote(sprint)
+}
+
+func updatedClause(since string) (string, error) {
+	if relativeDate.MatchString(since) {
+		return "updated >= -" + since, nil
+	}
+	if _, err := time.Parse(time.DateOnly, since); err == nil {
+		return "updated >= " + Quote(since), nil
+	}
+	return "", fmt.Errorf("invalid jira updated since %q, expected a duration such as 12h, 7d or 2w, or a date such as 2024-01-31", since)
+}
+
+// Quote quotes a JQL string value, e.g. a version name.
+func Quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
@@ -0,0 +1,92 @@
+package jira
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuery_SearchRequest(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    Query
+		expected string
+	}{
+		{
+			name:     "jql",
+			query:    Query{JQL: "labels = backend"},
+			expected: "(labels = backend)",
+		},
+		{
+			name:     "single values",
+			query:    Query{Projects: []string{"PROJ"}, Statuses: []string{"In Progress"}, Assignee: "me", Sprint: "current"},
+			expected: `project = "PROJ" AND status = "In Progress" AND assignee = currentUser() AND sprint in openSprints() AND sprint not in futureSprints()`,
+		},
+		{
+			name:     "multiple values",
+			query:    Query{Projects: []string{"PROJ", "OPS"}, Statuses: []string{"To Do", `Say "hi"`}},
+			expected: `project in ("PROJ", "OPS") AND status in ("To Do", "Say \"hi\"")`,
+		},
+		{
+			name:     "jql with order by",
+			query:    Query{JQL: "labels = backend or labels = api ORDER BY updated DESC", UpdatedSince: "7d"},
+			expected: "(labels = backend or labels = api) AND updated >= -7d ORDER BY updated DESC",
+		},
+		{
+			name:     "order by only",
+			query:    Query{JQL: "order by created", Assignee: "unassigned"},
+			expected: "assignee is EMPTY order by created",
+		},
+		{
+			name:     "sprint and assignee names",
+			query:    Query{Sprint: "Sprint 12", Assignee: "Jane Doe", UpdatedSince: "2024-01-31"},
+			expected: `assignee = "Jane Doe" AND sprint = "Sprint 12" AND updated >= "2024-01-31"`,
+		},
+		{
+			name:     "active sprint",
+			query:    Query{Sprint: "Active"},
+			expected: "sprint in openSprints() AND sprint not in futureSprints()",
+		},
+		{
+			name:     "open sprints",
+			query:    Query{Sprint: "open"},
+			expected: "sprint in openSprints()",
+		},
+		{
+			name:     "sprint id",
+			query:    Query{Sprint: "42"},
+			expected: "sprint = 42",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request, err := tc.query.SearchRequest()
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, request.JQL)
+		})
+	}
+}
+
+func TestQuery_SearchRequest_Fields(t *testing.T) {
+	request, err := Query{Projects: []string{"PROJ"}, Fields: []string{"summary", "status"}}.SearchRequest()
+	assert.NoError(t, err)
+	assert.Equal(t, SearchRequest{JQL: `project = "PROJ"`, Fields: []string{"summary", "status"}}, request)
+}
+
+func TestQuery_SearchRequest_Invalid(t *testing.T) {
+	_, err := Query{UpdatedSince: "last week"}.SearchRequest()
+	assert.EqualError(t, err, `invalid jira updated since "last week", expected a duration such as 12h, 7d or 2w, or a date such as 2024-01-31`)
+
+	_, err = Query{Projects: []string{"PROJ", " "}}.SearchRequest()
+	assert.EqualError(t, err, "jira project must not be empty")
+
+	_, err = Query{JQL: " "}.SearchRequest()
+	assert.EqualError(t, err, "jira query requires at least one criterion")
+}
+
+func TestQuery_IsZero(t *testing.T) {
+	assert.True(t, Query{}.IsZero())
+	assert.True(t, Query{Fields: []string{"summary"}}.IsZero())
+	assert.False(t, Query{Sprint: "current"}.IsZero())
+}
@@ -0,0 +1,492 @@
+package jira
+
+import "encoding/json"
+
+// SearchRequest is the body of a Jira search request.
+type SearchRequest struct {
+	JQL             string   `json:"jql"`
+	Fields          []string `json:"fields,omitempty"`
+	Expand          string   `json:"expand,omitempty"`
+	Properties      []string `json:"properties,omitempty"`
+	FieldsByKeys    bool     `json:"fieldsByKeys,omitempty"`
+	FailFast        bool     `json:"failFast,omitempty"`
+	ReconcileIssues []int    `json:"reconcileIssues,omitempty"`
+	MaxResults      int      `json:"maxResults,omitempty"`
+	NextPageToken   string   `json:"nextPageToken,omitempty"`
+
+	// MaxIssues caps the number of issues fetched across pages (0 for no limit).
+	MaxIssues int `json:"-"`
+}
+
+// SearchResponse is the result of a Jira search.
+type SearchResponse struct {
+	Expand        string            `json:"expand,omitempty"`
+	StartAt       *int              `json:"startAt,omitempty"`
+	MaxResults    *int              `json:"maxResults,omitempty"`
+	Total         *int              `json:"total,omitempty"`
+	Names         map[string]string `json:"names,omitempty"`
+	Issues        []Issue           `json:"issues"`
+	NextPageToken string            `json:"nextPageToken,omitempty"`
+	IsLast        bool              `json:"isLast,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Issue is a Jira issue.
+type Issue struct {
+	Expand string `json:"expand,omitempty"`
+	ID     string `json:"id,omitempty"`
+	Self   string `json:"self,omitempty"`
+	Key    string `json:"key"`
+	Fields Fields `json:"fields,omitzero"`
+
+	// Changelog is the history of the issue, returned with the changelog expand.
+	Changelog *Changelog `json:"changelog,omitempty"`
+
+	// Children are the issues whose parent is the issue, such as the issues of an epic, when fetched as a tree.
+	Children []Issue `json:"children,omitempty"`
+
+	// Extra holds the keys of the issue that are not modeled, such as renderedFields, marshalled back as is.
+	// Every other typed struct holds them likewise.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Fields are the fields of a Jira issue.
+type Fields struct {
+	Summary     string      `json:"summary,omitempty"`
+	Description Text        `json:"description,omitzero"`
+	IssueType   *IssueType  `json:"issuetype,omitempty"`
+	Status      *Status     `json:"status,omitempty"`
+	Priority    *Priority   `json:"priority,omitempty"`
+	Assignee    *User       `json:"assignee,omitempty"`
+	Reporter    *User       `json:"reporter,omitempty"`
+	Labels      []string    `json:"labels,omitempty"`
+	Created     string      `json:"created,omitempty"`
+	Updated     string      `json:"updated,omitempty"`
+	DueDate     string      `json:"duedate,omitempty"`
+	FixVersions []Version   `json:"fixVersions,omitempty"`
+	Comment     *Comments   `json:"comment,omitempty"`
+	Worklog     *Worklogs   `json:"worklog,omitempty"`
+	Parent      *Issue      `json:"parent,omitempty"`
+	Subtasks    []Issue     `json:"subtasks,omitempty"`
+	IssueLinks  []IssueLink `json:"issuelinks,omitempty"`
+
+	// Custom holds every other field, such as customfield_10020, keyed by its ID,
+	// along with the null or empty fields omitted from the typed ones, such as an unassigned assignee.
+	Custom map[string]json.RawMessage `json:"-"`
+}
+
+// User is a Jira user.
+type User struct {
+	Self         string `json:"self,omitempty"`
+	AccountID    string `json:"accountId,omitempty"`
+	Name         string `json:"name,omitempty"`
+	EmailAddress string `json:"emailAddress,omitempty"`
+	DisplayName  string `json:"displayName,omitempty"`
+	Active       *bool  `json:"active,omitempty"`
+	TimeZone     string `json:"timeZone,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Status is the workflow status of a Jira issue.
+type Status struct {
+	Self           string          `json:"self,omitempty"`
+	ID             string          `json:"id,omitempty"`
+	Name           string          `json:"name,omitempty"`
+	Description    string          `json:"description,omitempty"`
+	StatusCategory *StatusCategory `json:"statusCategory,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// StatusCategory groups statuses into to do, in progress and done.
+type StatusCategory struct {
+	Self      string `json:"self,omitempty"`
+	ID        int    `json:"id,omitempty"`
+	Key       string `json:"key,omitempty"`
+	Name      string `json:"name,omitempty"`
+	ColorName string `json:"colorName,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Priority is the priority of a Jira issue.
+type Priority struct {
+	Self string `json:"self,omitempty"`
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// IssueType is the type of a Jira issue.
+type IssueType struct {
+	Self    string `json:"self,omitempty"`
+	ID      string `json:"id,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Subtask *bool  `json:"subtask,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Version is a version of a Jira project, such as the fix version of an issue.
+type Version struct {
+	Self        string `json:"self,omitempty"`
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Released    *bool  `json:"released,omitempty"`
+	ReleaseDate string `json:"releaseDate,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// IssueLink links a Jira issue to either an inward or an outward issue.
+type IssueLink struct {
+	ID           string         `json:"id,omitempty"`
+	Self         string         `json:"self,omitempty"`
+	Type         *IssueLinkType `json:"type,omitempty"`
+	InwardIssue  *Issue         `json:"inwardIssue,omitempty"`
+	OutwardIssue *Issue         `json:"outwardIssue,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// IssueLinkType is the type of an issue link, e.g. blocks with inward is blocked by and outward blocks.
+type IssueLinkType struct {
+	ID      string `json:"id,omitempty"`
+	Self    string `json:"self,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Inward  string `json:"inward,omitempty"`
+	Outward string `json:"outward,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Comments are the comments of a Jira issue.
+type Comments struct {
+	StartAt    int       `json:"startAt"`
+	MaxResults int       `json:"maxResults"`
+	Total      int       `json:"total"`
+	Comments   []Comment `json:"comments"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Comment is a comment of a Jira issue.
+type Comment struct {
+	Self    string `json:"self,omitempty"`
+	ID      string `json:"id,omitempty"`
+	Author  *User  `json:"author,omitempty"`
+	Body    Text   `json:"body,omitzero"`
+	Created string `json:"created,omitempty"`
+	Updated string `json:"updated,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Worklogs are the work logged on a Jira issue.
+type Worklogs struct {
+	StartAt    int       `json:"startAt"`
+	MaxResults int       `json:"maxResults"`
+	Total      int       `json:"total"`
+	Worklogs   []Worklog `json:"worklogs"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Worklog is work logged on a Jira issue.
+type Worklog struct {
+	Self             string `json:"self,omitempty"`
+	ID               string `json:"id,omitempty"`
+	Author           *User  `json:"author,omitempty"`
+	Comment          Text   `json:"comment,omitzero"`
+	Started          string `json:"started,omitempty"`
+	TimeSpent        string `json:"timeSpent,omitempty"`
+	TimeSpentSeconds int    `json:"timeSpentSeconds,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// Changelog is the history of a Jira issue.
+type Changelog struct {
+	StartAt    int       `json:"startAt"`
+	MaxResults int       `json:"maxResults"`
+	Total      int       `json:"total"`
+	Histories  []History `json:"histories"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// History is a change of the fields of a Jira issue.
+type History struct {
+	ID      string       `json:"id,omitempty"`
+	Author  *User        `json:"author,omitempty"`
+	Created string       `json:"created,omitempty"`
+	Items   []ChangeItem `json:"items,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// ChangeItem is the change of a field of a Jira issue.
+type ChangeItem struct {
+	Field      string `json:"field,omitempty"`
+	FieldType  string `json:"fieldtype,omitempty"`
+	From       string `json:"from,omitempty"`
+	FromString string `json:"fromString,omitempty"`
+	To         string `json:"to,omitempty"`
+	ToString   string `json:"toString,omitempty"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+func (f *Fields) UnmarshalJSON(data []byte) error {
+	type fields Fields
+	return unmarshalExtra(data, (*fields)(f), &f.Custom)
+}
+
+func (f Fields) MarshalJSON() ([]byte, error) {
+	type fields Fields
+	return marshalExtra(fields(f), f.Custom)
+}
+
+// CustomField decodes the custom field with the given ID into v,
+// it returns false if the issue does not have such a field.
+func (f Fields) CustomField(id string, v any) (bool, error) {
+	value, ok := f.Custom[id]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(value, v)
+}
+
+// The typed structs keep the keys they do not model in their Extra,
+// so that the responses of Jira are marshalled back as they were returned.
+
+// unmarshalExtra unmarshals the data into v, a pointer to a struct without its UnmarshalJSON method,
+// and into extra the keys that v would not marshal back: the keys it does not model,
+// and the null or empty values omitted from its fields.
+func unmarshalExtra(data []byte, v any, extra *map[string]json.RawMessage) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+
+	typed, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var kept map[string]json.RawMessage
+	if err = json.Unmarshal(typed, &kept); err != nil {
+		return err
+	}
+
+	*extra = nil
+	for key, value := range all {
+		if _, ok := kept[key]; ok {
+			continue
+		}
+		if *extra == nil {
+			*extra = make(map[string]json.RawMessage)
+		}
+		(*extra)[key] = value
+	}
+	return nil
+}
+
+// marshalExtra marshals v, a struct without its MarshalJSON method, along with the extra keys
+// that its fields do not marshal.
+func marshalExtra(v any, extra map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+
+	var all map[string]json.RawMessage
+	if err = json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+
+	for key, value := range extra {
+		if _, ok := all[key]; !ok {
+			all[key] = value
+		}
+	}
+	return json.Marshal(all)
+}
+
+func (s *SearchResponse) UnmarshalJSON(data []byte) error {
+	type searchResponse SearchResponse
+	return unmarshalExtra(data, (*searchResponse)(s), &s.Extra)
+}
+
+func (s SearchResponse) MarshalJSON() ([]byte, error) {
+	type searchResponse SearchResponse
+	return marshalExtra(searchResponse(s), s.Extra)
+}
+
+func (i *Issue) UnmarshalJSON(data []byte) error {
+	type issue Issue
+	return unmarshalExtra(data, (*issue)(i), &i.Extra)
+}
+
+func (i Issue) MarshalJSON() ([]byte, error) {
+	type issue Issue
+	return marshalExtra(issue(i), i.Extra)
+}
+
+func (u *User) UnmarshalJSON(data []byte) error {
+	type user User
+	return unmarshalExtra(data, (*user)(u), &u.Extra)
+}
+
+func (u User) MarshalJSON() ([]byte, error) {
+	type user User
+	return marshalExtra(user(u), u.Extra)
+}
+
+func (s *Status) UnmarshalJSON(data []byte) error {
+	type status Status
+	return unmarshalExtra(data, (*status)(s), &s.Extra)
+}
+
+func (s Status) MarshalJSON() ([]byte, error) {
+	type status Status
+	return marshalExtra(status(s), s.Extra)
+}
+
+func (s *StatusCategory) UnmarshalJSON(data []byte) error {
+	type statusCategory StatusCategory
+	return unmarshalExtra(data, (*statusCategory)(s), &s.Extra)
+}
+
+func (s StatusCategory) MarshalJSON() ([]byte, error) {
+	type statusCategory StatusCategory
+	return marshalExtra(statusCategory(s), s.Extra)
+}
+
+func (p *Priority) UnmarshalJSON(data []byte) error {
+	type priority Priority
+	return unmarshalExtra(data, (*priority)(p), &p.Extra)
+}
+
+func (p Priority) MarshalJSON() ([]byte, error) {
+	type priority Priority
+	return marshalExtra(priority(p), p.Extra)
+}
+
+func (i *IssueType) UnmarshalJSON(data []byte) error {
+	type issueType IssueType
+	return unmarshalExtra(data, (*issueType)(i), &i.Extra)
+}
+
+func (i IssueType) MarshalJSON() ([]byte, error) {
+	type issueType IssueType
+	return marshalExtra(issueType(i), i.Extra)
+}
+
+func (v *Version) UnmarshalJSON(data []byte) error {
+	type version Version
+	return unmarshalExtra(data, (*version)(v), &v.Extra)
+}
+
+func (v Version) MarshalJSON() ([]byte, error) {
+	type version Version
+	return marshalExtra(version(v), v.Extra)
+}
+
+func (i *IssueLink) UnmarshalJSON(data []byte) error {
+	type issueLink IssueLink
+	return unmarshalExtra(data, (*issueLink)(i), &i.Extra)
+}
+
+func (i IssueLink) MarshalJSON() ([]byte, error) {
+	type issueLink IssueLink
+	return marshalExtra(issueLink(i), i.Extra)
+}
+
+func (i *IssueLinkType) UnmarshalJSON(data []byte) error {
+	type issueLinkType IssueLinkType
+	return unmarshalExtra(data, (*issueLinkType)(i), &i.Extra)
+}
+
+func (i IssueLinkType) MarshalJSON() ([]byte, error) {
+	type issueLinkType IssueLinkType
+	return marshalExtra(issueLinkType(i), i.Extra)
+}
+
+func (c *Comments) UnmarshalJSON(data []byte) error {
+	type comments Comments
+	return unmarshalExtra(data, (*comments)(c), &c.Extra)
+}
+
+func (c Comments) MarshalJSON() ([]byte, error) {
+	type comments Comments
+	return marshalExtra(comments(c), c.Extra)
+}
+
+func (c *Comment) UnmarshalJSON(data []byte) error {
+	type comment Comment
+	return unmarshalExtra(data, (*comment)(c), &c.Extra)
+}
+
+func (c Comment) MarshalJSON() ([]byte, error) {
+	type comment Comment
+	return marshalExtra(comment(c), c.Extra)
+}
+
+func (w *Worklogs) UnmarshalJSON(data []byte) error {
+	type worklogs Worklogs
+	return unmarshalExtra(data, (*worklogs)(w), &w.Extra)
+}
+
+func (w Worklogs) MarshalJSON() ([]byte, error) {
+	type worklogs Worklogs
+	return marshalExtra(worklogs(w), w.Extra)
+}
+
+func (w *Worklog) UnmarshalJSON(data []byte) error {
+	type worklog Worklog
+	return unmarshalExtra(data, (*worklog)(w), &w.Extra)
+}
+
+func (w Worklog) MarshalJSON() ([]byte, error) {
+	type worklog Worklog
+	return marshalExtra(worklog(w), w.Extra)
+}
+
+func (c *Changelog) UnmarshalJSON(data []byte) error {
+	type changelog Changelog
+	return unmarshalExtra(data, (*changelog)(c), &c.Extra)
+}
+
+func (c Changelog) MarshalJSON() ([]byte, error) {
+	type changelog Changelog
+	return marshalExtra(changelog(c), c.Extra)
+}
+
+func (h *History) UnmarshalJSON(data []byte) error {
+	type history History
+	return unmarshalExtra(data, (*history)(h), &h.Extra)
+}
+
+func (h History) MarshalJSON() ([]byte, error) {
+	type history History
+	return marshalExtra(history(h), h.Extra)
+}
+
+func (c *ChangeItem) UnmarshalJSON(data []byte) error {
+	type changeItem ChangeItem
+	return unmarshalExtra(data, (*changeItem)(c), &c.Extra)
+}
+
+func (c ChangeItem) MarshalJSON() ([]byte, error) {
+	type changeItem ChangeItem
+	return marshalExtra(changeItem(c), c.Extra)
+}
@@ -0,0 +1,317 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// wildcard matches any key of a projection path.
+const wildcard = "*"
+
+// Projection selects the fields of Jira issues.
+//
+// Included and excluded fields are paths from each issue, such as fields.status.name, whose keys can be * wildcards
+// and where lists are traversed, such as fields.components.name. Excluded fields without a path, such as self,
+// are excluded by name at any depth of the response instead.
+type Projection struct {
+	included [][]string
+	excluded [][]string
+	names    map[string]bool
+}
+
+// ParseProjection parses comma separated included and excluded fields,
+// every field being included if there is none.
+func ParseProjection(includedFields, excludedFields string) (Projection, error) {
+	p := Projection{names: make(map[string]bool)}
+
+	included, err := parsePaths(includedFields)
+	if err != nil {
+		return Projection{}, err
+	}
+	p.included = included
+
+	excluded, err := parsePaths(excludedFields)
+	if err != nil {
+		return Projection{}, err
+	}
+	for _, path := range excluded {
+		if len(path) == 1 && path[0] != wildcard {
+			p.names[path[0]] = true
+		} else {
+			p.excluded = append(p.excluded, path)
+		}
+	}
+	return p, nil
+}
+
+func parsePaths(fields string) ([][]string, error) {
+	var paths [][]string
+	for _, field := range strings.Split(fields, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+
+		path := strings.Split(field, ".")
+		if slices.Contains(path, "") {
+			return nil, fmt.Errorf("invalid Jira field path %q", field)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// Encode marshals the given search response, issues or issue to JSON with the fields of the projection.
+func (p Projection) Encode(v any) (string, error) {
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal Jira issues: %w", err)
+	}
+
+	var data any
+	if err = json.Unmarshal(jsonData, &data); err != nil {
+		return "", fmt.Errorf("failed to unmarshal Jira issues: %w", err)
+	}
+
+	p.apply(data)
+
+	if jsonData, err = json.Marshal(data); err != nil {
+		return "", fmt.Errorf("failed to marshal Jira issues: %w", err)
+	}
+
+	return string(jsonData), nil
+}
+
+// Issues returns the issues with the fields of the projection, e.g. to render them with a template.
+func (p Projection) Issues(issues []Issue) ([]Issue, error) {
+	data, err := p.Encode(issues)
+	if err != nil {
+		return nil, err
+	}
+
+	var projected []Issue
+	if err = json.Unmarshal([]byte(data), &projected); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Jira issues: %w", err)
+	}
+	return projected, nil
+}
+
+func (p Projection) apply(data any) {
+	if m, ok := data.(map[string]any); ok {
+		removeKeys(m, p.names)
+	}
+	if items, ok := data.([]any); ok {
+		for _, item := range items {
+			if m, ok := item.(map[string]any); ok {
+				removeKeys(m, p.names)
+			}
+		}
+	}
+
+	for _, issue := range issues(data) {
+		p.project(issue)
+	}
+}
+
+// project selects the fields of the issue, and of its related issues as issues themselves.
+// The related issues are kept even if not included, unless explicitly excluded, so that the relations of an issue tree are not lost.
+func (p Projection) project(issue map[string]any) {
+	related := detachRelations(issue)
+
+	if len(p.included) > 0 {
+		keep := slices.Clone(p.included)
+		// The key identifies the issue, so it is always included
+		keep = append(keep, []string{"key"})
+		includeKeys(issue, keep)
+	}
+
+	for _, path := range p.excluded {
+		excludeKeys(issue, path)
+	}
+
+	for _, r := range related {
+		if p.excludes(r) {
+			continue
+		}
+		r.project(p)
+		r.attach(issue)
+	}
+}
+
+// excludes reports whether the related issues are explicitly excluded, e.g. with fields.subtasks.
+func (p Projection) excludes(r relation) bool {
+	path := []string{"children"}
+	if r.field != "" {
+		path = []string{"fields", r.field}
+	}
+	return slices.ContainsFunc(p.excluded, func(excluded []string) bool {
+		return slices.Equal(excluded, path)
+	})
+}
+
+// relation holds the related issues of an issue, such as its children, subtasks, links or parent.
+type relation struct {
+	// field is the field of the issue fields holding the relation, or empty for the children of the issue.
+	field string
+	value any
+}
+
+// detachRelations removes the related issues from the issue, to be projected separately.
+func detachRelations(issue map[string]any) []relation {
+	var related []relation
+	if children, ok := issue["children"]; ok {
+		related = append(related, relation{value: children})
+		delete(issue, "children")
+	}
+
+	fields, ok := issue["fields"].(map[string]any)
+	if !ok {
+		return related
+	}
+
+	for _, field := range []string{"parent", "subtasks", "issuelinks"} {
+		if value, ok := fields[field]; ok {
+			related = append(related, relation{field: field, value: value})
+			delete(fields, field)
+		}
+	}
+	return related
+}
+
+// project projects the related issues, the links keeping their type along with the linked issues.
+func (r relation) project(p Projection) {
+	var items []any
+	switch v := r.value.(type) {
+	case map[string]any:
+		items = []any{v}
+	case []any:
+		items = v
+	}
+
+	for _, item := range items {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		if r.field != "issuelinks" {
+			p.project(m)
+			continue
+		}
+
+		for _, direction := range []string{"inwardIssue", "outwardIssue"} {
+			if linked, ok := m[direction].(map[string]any); ok {
+				p.project(linked)
+			}
+		}
+	}
+}
+
+// attach puts the related issues back in the issue.
+func (r relation) attach(issue map[string]any) {
+	if r.field == "" {
+		issue["children"] = r.value
+		return
+	}
+
+	fields, ok := issue["fields"].(map[string]any)
+	if !ok {
+		fields = make(map[string]any)
+		issue["fields"] = fields
+	}
+	fields[r.field] = r.value
+}
+
+// issues returns the issues of a search response, a list of issues or an issue.
+func issues(data any) []map[string]any {
+	if m, ok := data.(map[string]any); ok {
+		// A search response holds its issues, even if null when there are none
+		list, ok := m["issues"]
+		if !ok {
+			return []map[string]any{m}
+		}
+		data = list
+	}
+
+	var issues []map[string]any
+	if list, ok := data.([]any); ok {
+		for _, item := range list {
+			if issue, ok := item.(map[string]any); ok {
+				issues = append(issues, issue)
+			}
+		}
+	}
+	return issues
+}
+
+// includeKeys keeps the keys of the data on the given paths, along with everything under them.
+func includeKeys(data map[string]any, paths [][]string) {
+	for key, value := range data {
+		var rest [][]string
+		whole := false
+		for _, path := range paths {
+			if path[0] != key && path[0] != wildcard {
+				continue
+			}
+			if len(path) == 1 {
+				whole = true
+				break
+			}
+			rest = append(rest, path[1:])
+		}
+
+		switch {
+		case whole:
+		case len(rest) == 0:
+			delete(data, key)
+		default:
+			if !includeNested(value, rest) {
+				delete(data, key)
+			}
+		}
+	}
+}
+
+// includeNested keeps the keys of a nested map, or of the maps of a nested list,
+// and reports whether anything is left.
+func includeNested(value any, paths [][]string) bool {
+	switch v := value.(type) {
+	case map[string]any:
+		includeKeys(v, paths)
+		return len(v) > 0
+	case []any:
+		for _, item := range v {
+			includeNested(item, paths)
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// excludeKeys removes the keys of the data on the given path.
+func excludeKeys(data map[string]any, path []string) {
+	for key, value := range data {
+		if path[0] != key && path[0] != wildcard {
+			continue
+		}
+
+		if len(path) == 1 {
+			delete(data, key)
+			continue
+		}
+		excludeNested(value, path[1:])
+	}
+}
+
+func excludeNested(value any, path []string) {
+	switch v := value.(type) {
+	case map[string]any:
+		excludeKeys(v, path)
+	case []any:
+		for _, item := range v {
+			excludeNested(item, path)
+		}
+	}
+}
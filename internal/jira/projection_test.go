@@ -0,0 +1,162 @@
+package jira
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newProjectionResponse() *SearchResponse {
+	return &SearchResponse{
+		Expand: "names",
+		Issues: []Issue{
+			{
+				ID:   "10001",
+				Self: "https://jira/rest/api/2/issue/10001",
+				Key:  "PROJ-1",
+				Fields: Fields{
+					Summary:  "Summary",
+					Status:   &Status{Name: "In Progress", StatusCategory: &StatusCategory{Key: "indeterminate", Name: "In Progress"}},
+					Assignee: &User{AccountID: "1", DisplayName: "Jane Doe"},
+					Reporter: &User{AccountID: "2", DisplayName: "John Doe"},
+					Custom:   map[string]json.RawMessage{"components": json.RawMessage(`[{"id":"1","name":"API"},{"id":"2","name":"UI"}]`)},
+				},
+			},
+		},
+	}
+}
+
+func TestProjection_Encode(t *testing.T) {
+	testCases := []struct {
+		name     string
+		included string
+		excluded string
+		expected string
+	}{
+		{
+			name:     "excluded names",
+			excluded: "id,self,expand,name",
+			expected: `{"issues":[{"fields":{"assignee":{"accountId":"1","displayName":"Jane Doe"},"components":[{},{}],"reporter":{"accountId":"2","displayName":"John Doe"},"status":{"statusCategory":{"key":"indeterminate"}},"summary":"Summary"},"key":"PROJ-1"}]}`,
+		},
+		{
+			name:     "excluded paths",
+			excluded: "id,self,expand,fields.status.statusCategory,fields.*.accountId,fields.components.id",
+			expected: `{"issues":[{"fields":{"assignee":{"displayName":"Jane Doe"},"components":[{"name":"API"},{"name":"UI"}],"reporter":{"displayName":"John Doe"},"status":{"name":"In Progress"},"summary":"Summary"},"key":"PROJ-1"}]}`,
+		},
+		{
+			name:     "included paths",
+			included: "fields.summary,fields.status.name,fields.assignee.displayName",
+			expected: `{"expand":"names","issues":[{"fields":{"assignee":{"displayName":"Jane Doe"},"status":{"name":"In Progress"},"summary":"Summary"},"key":"PROJ-1"}]}`,
+		},
+		{
+			name:     "included wildcards and lists",
+			included: "fields.*.displayName,fields.components.name",
+			excluded: "expand",
+			expected: `{"issues":[{"fields":{"assignee":{"displayName":"Jane Doe"},"components":[{"name":"API"},{"name":"UI"}],"reporter":{"displayName":"John Doe"}},"key":"PROJ-1"}]}`,
+		},
+		{
+			name:     "included and excluded paths",
+			included: "fields.status",
+			excluded: "expand,fields.status.statusCategory.key",
+			expected: `{"issues":[{"fields":{"status":{"name":"In Progress","statusCategory":{"name":"In Progress"}}},"key":"PROJ-1"}]}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			projection, err := ParseProjection(tc.included, tc.excluded)
+			assert.NoError(t, err)
+
+			data, err := projection.Encode(newProjectionResponse())
+			assert.NoError(t, err)
+			assert.JSONEq(t, tc.expected, data)
+		})
+	}
+}
+
+func TestProjection_Encode_Issue(t *testing.T) {
+	projection, err := ParseProjection("fields.summary", "")
+	assert.NoError(t, err)
+
+	data, err := projection.Encode(newProjectionResponse().Issues[0])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"fields":{"summary":"Summary"},"key":"PROJ-1"}`, data)
+}
+
+func TestProjection_Encode_NoIssues(t *testing.T) {
+	projection, err := ParseProjection("fields.summary", "")
+	assert.NoError(t, err)
+
+	data, err := projection.Encode(&SearchResponse{Expand: "names"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"expand":"names","issues":null}`, data, "Expected a response without issues not to be projected as an issue")
+}
+
+func TestProjection_Issues(t *testing.T) {
+	projection, err := ParseProjection("fields.status.name,fields.components", "")
+	assert.NoError(t, err)
+
+	issues, err := projection.Issues(newProjectionResponse().Issues)
+	assert.NoError(t, err)
+	assert.Equal(t, []Issue{
+		{
+			Key: "PROJ-1",
+			Fields: Fields{
+				Status: &Status{Name: "In Progress"},
+				Custom: map[string]json.RawMessage{"components": json.RawMessage(`[{"id":"1","name":"API"},{"id":"2","name":"UI"}]`)},
+			},
+		},
+	}, issues)
+}
+
+func TestParseProjection_Invalid(t *testing.T) {
+	_, err := ParseProjection("fields..summary", "")
+	assert.EqualError(t, err, `invalid Jira field path "fields..summary"`)
+}
+
+func TestProjection_Encode_Tree(t *testing.T) {
+	tree := Issue{
+		Key: "PROJ-1",
+		Fields: Fields{
+			Summary:  "Epic",
+			Status:   &Status{Name: "In Progress"},
+			Subtasks: []Issue{{Key: "PROJ-2", Fields: Fields{Summary: "Subtask", Status: &Status{Name: "Done"}}}},
+			IssueLinks: []IssueLink{{
+				Type:         &IssueLinkType{Name: "Blocks"},
+				OutwardIssue: &Issue{Key: "PROJ-3", Fields: Fields{Summary: "Blocked", Status: &Status{Name: "To Do"}}},
+			}},
+		},
+		Children: []Issue{{Key: "PROJ-4", Fields: Fields{Summary: "Story", Status: &Status{Name: "To Do"}}}},
+	}
+
+	// The related issues are kept, with the included fields only
+	projection, err := ParseProjection("fields.summary", "")
+	assert.NoError(t, err)
+
+	data, err := projection.Encode(tree)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"key": "PROJ-1",
+		"fields": {
+			"summary": "Epic",
+			"subtasks": [{"key": "PROJ-2", "fields": {"summary": "Subtask"}}],
+			"issuelinks": [{"type": {"name": "Blocks"}, "outwardIssue": {"key": "PROJ-3", "fields": {"summary": "Blocked"}}}]
+		},
+		"children": [{"key": "PROJ-4", "fields": {"summary": "Story"}}]
+	}`, data)
+
+	// Unless explicitly excluded
+	projection, err = ParseProjection("fields.summary", "fields.subtasks,children")
+	assert.NoError(t, err)
+
+	data, err = projection.Encode(tree)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"key": "PROJ-1",
+		"fields": {
+			"summary": "Epic",
+			"issuelinks": [{"type": {"name": "Blocks"}, "outwardIssue": {"key": "PROJ-3", "fields": {"summary": "Blocked"}}}]
+		}
+	}`, data)
+}
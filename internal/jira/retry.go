@@ -0,0 +1,123 @@
+package jira
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"go.uber.org/zap"
+)
+
+// Defaults of the retries of Jira requests.
+const (
+	DefaultRetryCount       = 3
+	DefaultRetryWaitTime    = time.Second
+	DefaultRetryMaxWaitTime = 30 * time.Second
+)
+
+// WithRetry retries rate limited and failed requests up to count times (0 for no retry),
+// waiting with an exponential backoff between wait and maxWait unless Jira tells how long to wait.
+func (j *Jira) WithRetry(count int, wait, maxWait time.Duration) *Jira {
+	j.restClient.
+		SetRetryCount(count).
+		SetRetryWaitTime(wait).
+		SetRetryMaxWaitTime(maxWait)
+	return j
+}
+
+// retryCondition retries rate limited requests, transient server errors and network errors,
+// but not errors before sending the request, such as failing to authenticate, for which there is no response.
+func retryCondition(res *resty.Response, err error) bool {
+	if err != nil {
+		return res != nil
+	}
+
+	switch res.StatusCode() {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryHook logs each retry.
+func retryHook(res *resty.Response, err error) {
+	if err != nil {
+		zap.S().Debugf("🔁 Retrying Jira request after error: %v", err)
+		return
+	}
+	zap.S().Debugf("🔁 Retrying Jira request %s after %s (attempt %d)", res.Request.URL, res.Status(), res.Request.Attempt)
+}
+
+// retryAfter returns how long to wait before retrying, as told by Jira or else with an exponential backoff.
+func retryAfter(c *resty.Client, res *resty.Response) (time.Duration, error) {
+	wait := rateLimitWait(res.Header(), time.Now())
+	if wait == 0 {
+		wait = backoff(c.RetryWaitTime, c.RetryMaxWaitTime, res.Request.Attempt)
+	}
+
+	if c.RetryMaxWaitTime > 0 && wait > c.RetryMaxWaitTime {
+		wait = c.RetryMaxWaitTime
+	}
+
+	zap.S().Debugf("⏳ Waiting %s before retrying", wait)
+	return wait, nil
+}
+
+// rateLimitWait returns how long Jira tells to wait with the Retry-After or X-RateLimit-* headers, 0 if it does not.
+func rateLimitWait(header http.Header, now time.Time) time.Duration {
+	var wait time.Duration
+	if retryAfter := header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			wait = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(retryAfter); err == nil {
+			wait = date.Sub(now)
+		}
+	} else if header.Get("X-RateLimit-Remaining") == "0" {
+		reset := header.Get("X-RateLimit-Reset")
+		for _, layout := range rateLimitResetLayouts {
+			if date, err := time.Parse(layout, reset); err == nil {
+				wait = date.Sub(now)
+				break
+			}
+		}
+	}
+
+	return max(wait, 0)
+}
+
+// rateLimitResetLayouts are the layouts of the X-RateLimit-Reset header, which Jira sends without seconds.
+var rateLimitResetLayouts = []string{"2006-01-02T15:04Z07:00", time.RFC3339}
+
+// backoff returns an exponential backoff for the attempt with jitter, between wait and maxWait.
+func backoff(wait, maxWait time.Duration, attempt int) time.Duration {
+	d := wait << max(attempt-1, 0)
+	if d <= 0 || (maxWait > 0 && d > maxWait) {
+		// Either capped or overflowed
+		d = maxWait
+	}
+
+	// Jitter between half and the whole of the backoff
+	half := d / 2
+	if half <= 0 {
+		return max(d, wait)
+	}
+	return max(half+rand.N(half), wait)
+}
+
+// restyLogger forwards the logs of resty to the debug logs, as errors are returned anyway.
+type restyLogger struct{}
+
+func (restyLogger) Errorf(format string, v ...interface{}) {
+	zap.S().Debugf(format, v...)
+}
+
+func (restyLogger) Warnf(format string, v ...interface{}) {
+	zap.S().Debugf(format, v...)
+}
+
+func (restyLogger) Debugf(format string, v ...interface{}) {
+	zap.S().Debugf(format, v...)
+}
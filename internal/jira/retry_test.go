@@ -0,0 +1,177 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newFlakyServer creates a mock server failing with the given status codes before succeeding.
+func newFlakyServer(t *testing.T, header http.Header, failures ...int) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempt := int(requests.Add(1))
+		if attempt <= len(failures) {
+			for key, values := range header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(failures[attempt-1])
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"issues":[{"key":"PROJ-1"}],"isLast":true}`)
+	}))
+	t.Cleanup(mockServer.Close)
+	return mockServer, &requests
+}
+
+// TestSearchIssues_Retry tests that rate limited and failed requests are retried.
+func TestSearchIssues_Retry(t *testing.T) {
+	testCases := []struct {
+		name             string
+		header           http.Header
+		failures         []int
+		expectedRequests int32
+		expectedError    string
+	}{
+		{
+			name:             "rateLimited",
+			header:           http.Header{"Retry-After": []string{"0"}},
+			failures:         []int{http.StatusTooManyRequests, http.StatusTooManyRequests},
+			expectedRequests: 3,
+		},
+		{
+			name:             "transientServerErrors",
+			failures:         []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusGatewayTimeout},
+			expectedRequests: 4,
+		},
+		{
+			name:             "tooManyFailures",
+			failures:         []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError},
+			expectedRequests: 4,
+			expectedError:    "failed to search for Jira issues: 500 Internal Server Error",
+		},
+		{
+			name:             "clientError",
+			failures:         []int{http.StatusBadRequest},
+			expectedRequests: 1,
+			expectedError:    "failed to search for Jira issues: 400 Bad Request",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockServer, requests := newFlakyServer(t, tc.header, tc.failures...)
+
+			j := New(mockServer.URL, "test-auth-token").WithRetry(3, time.Millisecond, 10*time.Millisecond)
+
+			res, err := j.SearchIssues(context.Background(), SearchRequest{JQL: "project=PROJ"})
+			assert.Equal(t, tc.expectedRequests, requests.Load(), "Unexpected number of requests")
+
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, res.Issues, 1)
+		})
+	}
+}
+
+// TestSearchIssues_NoRetry tests that retries can be disabled.
+func TestSearchIssues_NoRetry(t *testing.T) {
+	mockServer, requests := newFlakyServer(t, nil, http.StatusServiceUnavailable)
+
+	j := New(mockServer.URL, "test-auth-token").WithRetry(0, time.Millisecond, time.Millisecond)
+
+	_, err := j.SearchIssues(context.Background(), SearchRequest{JQL: "project=PROJ"})
+	assert.EqualError(t, err, "failed to search for Jira issues: 503 Service Unavailable")
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestRateLimitWait(t *testing.T) {
+	now := time.Date(2024, 1, 31, 9, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		header   http.Header
+		expected time.Duration
+	}{
+		{
+			name:     "noHeader",
+			header:   http.Header{},
+			expected: 0,
+		},
+		{
+			name:     "retryAfterSeconds",
+			header:   http.Header{"Retry-After": []string{"5"}},
+			expected: 5 * time.Second,
+		},
+		{
+			name:     "retryAfterDate",
+			header:   http.Header{"Retry-After": []string{"Wed, 31 Jan 2024 09:30:10 GMT"}},
+			expected: 10 * time.Second,
+		},
+		{
+			name:     "retryAfterInvalid",
+			header:   http.Header{"Retry-After": []string{"soon"}},
+			expected: 0,
+		},
+		{
+			name: "rateLimitReset",
+			header: http.Header{
+				"X-Ratelimit-Remaining": []string{"0"},
+				"X-Ratelimit-Reset":     []string{"2024-01-31T09:30:20Z"},
+			},
+			expected: 20 * time.Second,
+		},
+		{
+			// As sent by Jira, without seconds
+			name: "rateLimitResetMinutes",
+			header: http.Header{
+				"X-Ratelimit-Remaining": []string{"0"},
+				"X-Ratelimit-Reset":     []string{"2024-01-31T09:31Z"},
+			},
+			expected: time.Minute,
+		},
+		{
+			name: "rateLimitNotReached",
+			header: http.Header{
+				"X-Ratelimit-Remaining": []string{"10"},
+				"X-Ratelimit-Reset":     []string{"2024-01-31T09:30:20Z"},
+			},
+			expected: 0,
+		},
+		{
+			name:     "retryAfterInThePast",
+			header:   http.Header{"Retry-After": []string{"Wed, 31 Jan 2024 09:29:00 GMT"}},
+			expected: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, rateLimitWait(tc.header, now))
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	wait, maxWait := 100*time.Millisecond, time.Second
+
+	for attempt, expected := range []time.Duration{100, 100, 200, 400, 800, 1000, 1000} {
+		expected *= time.Millisecond
+		for range 10 {
+			d := backoff(wait, maxWait, attempt)
+			assert.GreaterOrEqual(t, d, max(expected/2, wait), "Backoff of attempt %d is too short", attempt)
+			assert.LessOrEqual(t, d, expected, "Backoff of attempt %d is too long", attempt)
+		}
+	}
+}
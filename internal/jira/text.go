@@ -0,0 +1,178 @@
+package jira
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// Text is a rich text field of a Jira issue, such as the description:
+// wiki markup with the v2 API, or an Atlassian Document Format (ADF) document with the v3 API.
+type Text struct {
+	// Markup is the wiki markup, or the text the document was converted to.
+	Markup string
+	// Document is the ADF document, if any.
+	Document *ADFNode
+}
+
+// IsZero reports whether the text is empty.
+func (t Text) IsZero() bool {
+	return t.Markup == "" && t.Document == nil
+}
+
+// Markdown renders the text as Markdown.
+func (t Text) Markdown() string {
+	if t.Document != nil {
+		return ADFToMarkdown(t.Document)
+	}
+	return WikiToMarkdown(t.Markup)
+}
+
+// Plain renders the text as plain text.
+func (t Text) Plain() string {
+	if t.Document != nil {
+		return ADFToText(t.Document)
+	}
+	return WikiToText(t.Markup)
+}
+
+// String returns the markup, or the document rendered as Markdown, e.g. when printed by a template.
+func (t Text) String() string {
+	if t.Document != nil {
+		return ADFToMarkdown(t.Document)
+	}
+	return t.Markup
+}
+
+// Convert converts the text to the format, the raw format leaving it as is.
+func (t Text) Convert(format TextFormat) Text {
+	switch format {
+	case TextMarkdown:
+		return Text{Markup: t.Markdown()}
+	case TextPlain:
+		return Text{Markup: t.Plain()}
+	default:
+		return t
+	}
+}
+
+func (t *Text) UnmarshalJSON(data []byte) error {
+	*t = Text{}
+	switch data = bytes.TrimSpace(data); {
+	case bytes.Equal(data, []byte("null")):
+		return nil
+	case len(data) > 0 && data[0] == '{':
+		t.Document = &ADFNode{}
+		return json.Unmarshal(data, t.Document)
+	default:
+		return json.Unmarshal(data, &t.Markup)
+	}
+}
+
+func (t Text) MarshalJSON() ([]byte, error) {
+	if t.Document != nil {
+		return json.Marshal(t.Document)
+	}
+	return json.Marshal(t.Markup)
+}
+
+// TextFormat is the format rich text fields are converted to.
+type TextFormat string
+
+const (
+	// TextRaw leaves rich text fields as returned by Jira.
+	TextRaw TextFormat = "raw"
+	// TextMarkdown converts rich text fields to Markdown.
+	TextMarkdown TextFormat = "markdown"
+	// TextPlain converts rich text fields to plain text.
+	TextPlain TextFormat = "plain"
+)
+
+// ParseTextFormat parses a text format name.
+func ParseTextFormat(name string) (TextFormat, error) {
+	switch format := TextFormat(name); format {
+	case TextRaw, TextMarkdown, TextPlain:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown text format %q, expected one of %s, %s or %s", name, TextRaw, TextMarkdown, TextPlain)
+	}
+}
+
+// ConvertText returns the issues with their rich text fields converted to the format, namely the description,
+// the bodies of the comments, the comments of the worklogs and the custom fields holding an ADF document,
+// along with those of their subtasks, linked issues and children.
+func ConvertText(issues []Issue, format TextFormat) []Issue {
+	if format == TextRaw || issues == nil {
+		return issues
+	}
+
+	converted := make([]Issue, len(issues))
+	for i, issue := range issues {
+		converted[i] = convertIssue(issue, format)
+	}
+	return converted
+}
+
+func convertIssue(issue Issue, format TextFormat) Issue {
+	issue.Fields.Description = issue.Fields.Description.Convert(format)
+
+	if issue.Fields.Comment != nil {
+		comments := *issue.Fields.Comment
+		comments.Comments = slices.Clone(comments.Comments)
+		for j := range comments.Comments {
+			comments.Comments[j].Body = comments.Comments[j].Body.Convert(format)
+		}
+		issue.Fields.Comment = &comments
+	}
+
+	if issue.Fields.Worklog != nil {
+		worklogs := *issue.Fields.Worklog
+		worklogs.Worklogs = slices.Clone(worklogs.Worklogs)
+		for j := range worklogs.Worklogs {
+			worklogs.Worklogs[j].Comment = worklogs.Worklogs[j].Comment.Convert(format)
+		}
+		issue.Fields.Worklog = &worklogs
+	}
+
+	if len(issue.Fields.Custom) > 0 {
+		custom := make(map[string]json.RawMessage, len(issue.Fields.Custom))
+		for id, value := range issue.Fields.Custom {
+			custom[id] = convertCustomField(value, format)
+		}
+		issue.Fields.Custom = custom
+	}
+
+	issue.Fields.Subtasks = ConvertText(issue.Fields.Subtasks, format)
+	issue.Children = ConvertText(issue.Children, format)
+
+	if issue.Fields.IssueLinks != nil {
+		links := slices.Clone(issue.Fields.IssueLinks)
+		for i, link := range links {
+			for _, linked := range []**Issue{&link.InwardIssue, &link.OutwardIssue} {
+				if *linked != nil {
+					converted := convertIssue(**linked, format)
+					*linked = &converted
+				}
+			}
+			links[i] = link
+		}
+		issue.Fields.IssueLinks = links
+	}
+	return issue
+}
+
+// convertCustomField converts a custom field holding an ADF document, such as a v3 paragraph field,
+// leaving any other value as is.
+func convertCustomField(value json.RawMessage, format TextFormat) json.RawMessage {
+	var doc ADFNode
+	if err := json.Unmarshal(value, &doc); err != nil || doc.Type != "doc" {
+		return value
+	}
+
+	data, err := json.Marshal(Text{Document: &doc}.Convert(format))
+	if err != nil {
+		return value
+	}
+	return data
+}
@@ -0,0 +1,65 @@
+package jira
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestText_JSON(t *testing.T) {
+	var fields Fields
+	err := json.Unmarshal([]byte(`{"description": {"type": "doc", "version": 1, "content": [{"type": "paragraph", "content": [{"type": "text", "text": "Hello"}]}]}}`), &fields)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello", fields.Description.Markdown())
+	assert.Empty(t, fields.Custom)
+
+	data, err := json.Marshal(fields)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"description": {"type": "doc", "version": 1, "content": [{"type": "paragraph", "content": [{"type": "text", "text": "Hello"}]}]}}`, string(data))
+
+	err = json.Unmarshal([]byte(`{"description": "*Hello*"}`), &fields)
+	assert.NoError(t, err)
+	assert.Equal(t, Text{Markup: "*Hello*"}, fields.Description)
+	assert.Equal(t, "**Hello**", fields.Description.Markdown())
+	assert.Equal(t, "Hello", fields.Description.Plain())
+
+	data, err = json.Marshal(Fields{})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(data))
+}
+
+func TestConvertText(t *testing.T) {
+	issues := []Issue{
+		{
+			Key: "PROJ-1",
+			Fields: Fields{
+				Description: Text{Markup: "h1. Title"},
+				Custom: map[string]json.RawMessage{
+					"customfield_10001": json.RawMessage(`{"type":"doc","version":1,"content":[{"type":"paragraph","content":[{"type":"text","text":"Notes","marks":[{"type":"strong"}]}]}]}`),
+					"customfield_10002": json.RawMessage(`{"type":"option","value":"High"}`),
+				},
+			},
+		},
+	}
+
+	converted := ConvertText(issues, TextMarkdown)
+	assert.Equal(t, Text{Markup: "# Title"}, converted[0].Fields.Description)
+	assert.JSONEq(t, `"**Notes**"`, string(converted[0].Fields.Custom["customfield_10001"]))
+	assert.JSONEq(t, `{"type":"option","value":"High"}`, string(converted[0].Fields.Custom["customfield_10002"]))
+	assert.Equal(t, Text{Markup: "h1. Title"}, issues[0].Fields.Description)
+
+	converted = ConvertText(issues, TextPlain)
+	assert.Equal(t, Text{Markup: "Title"}, converted[0].Fields.Description)
+
+	assert.Equal(t, issues, ConvertText(issues, TextRaw))
+}
+
+func TestParseTextFormat(t *testing.T) {
+	format, err := ParseTextFormat("plain")
+	assert.NoError(t, err)
+	assert.Equal(t, TextPlain, format)
+
+	_, err = ParseTextFormat("html")
+	assert.EqualError(t, err, `unknown text format "html", expected one of raw, markdown or plain`)
+}
@@ -0,0 +1,295 @@
+package jira
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	wikiHeading   = regexp.MustCompile(`^h([1-6])\.\s+(.*)$`)
+	wikiQuote     = regexp.MustCompile(`^bq\.\s+(.*)$`)
+	wikiList      = regexp.MustCompile(`^([*#-]+)\s+(.*)$`)
+	wikiBlock     = regexp.MustCompile(`^\{(code|noformat|quote|panel)(?::([^}]*))?\}(.*)$`)
+	wikiTableRow  = regexp.MustCompile(`^\|\|?.*\|$`)
+	wikiMonospace = regexp.MustCompile(`\{\{(.+?)\}\}`)
+	wikiMention   = regexp.MustCompile(`\[~(?:accountid:)?([^\]]+)\]`)
+	wikiLink      = regexp.MustCompile(`\[([^|\]]+)\|([^\]]+)\]`)
+	wikiURL       = regexp.MustCompile(`\[((?:https?|mailto):[^\]]+)\]`)
+	wikiColor     = regexp.MustCompile(`\{color(?::[^}]*)?\}`)
+)
+
+// WikiToMarkdown converts Jira wiki markup, as returned for rich text fields by the v2 API, to Markdown.
+func WikiToMarkdown(markup string) string {
+	return wikiConverter{}.convert(markup)
+}
+
+// WikiToText converts Jira wiki markup to plain text.
+func WikiToText(markup string) string {
+	return wikiConverter{plain: true}.convert(markup)
+}
+
+type wikiConverter struct {
+	plain bool
+}
+
+func (c wikiConverter) convert(markup string) string {
+	var lines []string
+	// block is the enclosing {code}, {noformat}, {quote} or {panel} block, if any
+	var block string
+	header := false
+
+	for _, line := range strings.Split(strings.ReplaceAll(markup, "\r\n", "\n"), "\n") {
+		if block == "code" || block == "noformat" {
+			if before, ok := cutBlockEnd(line, block); ok {
+				if before != "" {
+					lines = append(lines, before)
+				}
+				if !c.plain {
+					lines = append(lines, "```")
+				}
+				block = ""
+				continue
+			}
+			lines = append(lines, line)
+			continue
+		}
+
+		if block != "" {
+			if before, ok := cutBlockEnd(line, block); ok {
+				if before = strings.TrimSpace(before); before != "" {
+					lines = append(lines, c.quote(c.inline(before)))
+				}
+				block = ""
+				continue
+			}
+		}
+
+		if m := wikiBlock.FindStringSubmatch(line); m != nil && block == "" {
+			block = m[1]
+			rest := m[3]
+			if before, ok := cutBlockEnd(rest, block); ok {
+				// A block on a single line, e.g. {quote}text{quote}
+				rest, block = before, ""
+				if m[1] == "code" || m[1] == "noformat" {
+					if c.plain {
+						lines = append(lines, rest)
+					} else {
+						lines = append(lines, "`"+rest+"`")
+					}
+					continue
+				}
+			}
+
+			switch m[1] {
+			case "code", "noformat":
+				if !c.plain {
+					lines = append(lines, "```"+codeLanguage(m[1], m[2]))
+				}
+				if rest != "" {
+					lines = append(lines, rest)
+				}
+			default:
+				if title := blockTitle(m[2]); title != "" {
+					lines = append(lines, c.quote(c.bold(title)))
+				}
+				if rest = strings.TrimSpace(rest); rest != "" {
+					lines = append(lines, c.quote(c.inline(rest)))
+				}
+			}
+			continue
+		}
+
+		converted := c.line(line, &header)
+		if block != "" {
+			converted = c.quote(converted)
+		}
+		lines = append(lines, converted)
+	}
+
+	// An unterminated code block is closed
+	if (block == "code" || block == "noformat") && !c.plain {
+		lines = append(lines, "```")
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// line converts a line outside of code blocks, header being whether the previous line was a table header.
+func (c wikiConverter) line(line string, header *bool) string {
+	trimmed := strings.TrimSpace(line)
+	wasHeader := *header
+	*header = false
+
+	if m := wikiHeading.FindStringSubmatch(trimmed); m != nil {
+		if c.plain {
+			return c.inline(m[2])
+		}
+		return strings.Repeat("#", int(m[1][0]-'0')) + " " + c.inline(m[2])
+	}
+
+	if m := wikiQuote.FindStringSubmatch(trimmed); m != nil {
+		return c.quote(c.inline(m[1]))
+	}
+
+	if trimmed == "----" {
+		if c.plain {
+			return ""
+		}
+		return "---"
+	}
+
+	if m := wikiList.FindStringSubmatch(trimmed); m != nil {
+		depth := len(m[1])
+		marker := "- "
+		if m[1][depth-1] == '#' {
+			marker = "1. "
+		}
+		return strings.Repeat("  ", depth-1) + marker + c.inline(m[2])
+	}
+
+	if wikiTableRow.MatchString(trimmed) {
+		return c.tableRow(trimmed, header, wasHeader)
+	}
+
+	return c.inline(line)
+}
+
+// tableRow converts a table row, ||header||cells|| or |cells|, adding the Markdown separator after the header.
+func (c wikiConverter) tableRow(row string, header *bool, afterHeader bool) string {
+	isHeader := strings.HasPrefix(row, "||")
+	separator := "|"
+	if isHeader {
+		separator = "||"
+	}
+
+	cells := strings.Split(strings.TrimSuffix(strings.TrimPrefix(row, separator), separator), separator)
+	for i, cell := range cells {
+		cells[i] = c.inline(strings.TrimSpace(cell))
+	}
+
+	if c.plain {
+		return strings.Join(cells, " | ")
+	}
+
+	line := "| " + strings.Join(cells, " | ") + " |"
+	if isHeader && !afterHeader {
+		*header = true
+		line += "\n|" + strings.Repeat(" --- |", len(cells))
+	}
+	return line
+}
+
+// inline converts the inline markup of text, such as effects, links and mentions.
+func (c wikiConverter) inline(text string) string {
+	text = wikiColor.ReplaceAllString(text, "")
+	text = strings.ReplaceAll(text, `\\`, "\n")
+
+	if c.plain {
+		text = wikiMonospace.ReplaceAllString(text, "$1")
+		text = wikiMention.ReplaceAllString(text, "@$1")
+		text = wikiLink.ReplaceAllString(text, "$1 ($2)")
+		text = wikiURL.ReplaceAllString(text, "$1")
+		for _, delimiter := range []string{"*", "_", "-", "+", "??"} {
+			text = replaceEffect(text, delimiter, "", "")
+		}
+		return text
+	}
+
+	text = wikiMonospace.ReplaceAllString(text, "`$1`")
+	text = wikiMention.ReplaceAllString(text, "@$1")
+	text = wikiLink.ReplaceAllString(text, "[$1]($2)")
+	text = wikiURL.ReplaceAllString(text, "<$1>")
+	text = replaceEffect(text, "*", "**", "**")
+	text = replaceEffect(text, "_", "*", "*")
+	text = replaceEffect(text, "-", "~~", "~~")
+	text = replaceEffect(text, "+", "", "")
+	text = replaceEffect(text, "??", "*", "*")
+	return text
+}
+
+func (c wikiConverter) quote(text string) string {
+	if c.plain {
+		return text
+	}
+	return strings.TrimRight("> "+text, " ")
+}
+
+func (c wikiConverter) bold(text string) string {
+	if c.plain {
+		return text + ":"
+	}
+	return "**" + text + "**"
+}
+
+// replaceEffect replaces the text effect surrounded by the delimiter, such as *bold*, with the given open and close.
+// Like Jira, the delimiters must not be surrounded by letters or digits outside, nor by spaces inside.
+func replaceEffect(text, delimiter, open, close string) string {
+	var sb strings.Builder
+	for {
+		start := strings.Index(text, delimiter)
+		if start < 0 {
+			break
+		}
+
+		inner := text[start+len(delimiter):]
+		end := strings.Index(inner, delimiter)
+		if end <= 0 || !effectStart(text[:start], inner) || !effectEnd(inner[:end], inner[end+len(delimiter):]) {
+			sb.WriteString(text[:start+len(delimiter)])
+			text = inner
+			continue
+		}
+
+		sb.WriteString(text[:start])
+		sb.WriteString(open + inner[:end] + close)
+		text = inner[end+len(delimiter):]
+	}
+	sb.WriteString(text)
+	return sb.String()
+}
+
+func effectStart(before, inner string) bool {
+	last, _ := utf8.DecodeLastRuneInString(before)
+	first, _ := utf8.DecodeRuneInString(inner)
+	return (before == "" || !isWordRune(last)) && !unicode.IsSpace(first)
+}
+
+func effectEnd(inner, after string) bool {
+	last, _ := utf8.DecodeLastRuneInString(inner)
+	first, _ := utf8.DecodeRuneInString(after)
+	return !unicode.IsSpace(last) && (after == "" || !isWordRune(first))
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// cutBlockEnd cuts the line before the end of the block, e.g. {code}.
+func cutBlockEnd(line, block string) (string, bool) {
+	before, _, ok := strings.Cut(line, "{"+block+"}")
+	return before, ok
+}
+
+// codeLanguage returns the language of a {code:language} block.
+func codeLanguage(block, params string) string {
+	if block != "code" {
+		return ""
+	}
+	for _, param := range strings.Split(params, "|") {
+		if param != "" && !strings.Contains(param, "=") {
+			return param
+		}
+	}
+	return ""
+}
+
+// blockTitle returns the title of a {panel:title=...} block.
+func blockTitle(params string) string {
+	for _, param := range strings.Split(params, "|") {
+		if title, ok := strings.CutPrefix(param, "title="); ok {
+			return title
+		}
+	}
+	return ""
+}
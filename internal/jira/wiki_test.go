@@ -0,0 +1,66 @@
+package jira
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const wikiMarkup = `h2. Context
+Ask [~jane.doe] about the *API*, see [the docs|https://example.com] and {{run}} it _now_.
+Keep well-known words -removed- and 2*3*4 as is.
+
+* first
+** nested
+# numbered
+
+{code:go}
+fmt.Println("*hi*")
+{code}
+{panel:title=Warning}
+Deploy on Monday
+{panel}
+||Env||Status||
+|prod|up|
+bq. quoted`
+
+func TestWikiToMarkdown(t *testing.T) {
+	assert.Equal(t, `## Context
+Ask @jane.doe about the **API**, see [the docs](https://example.com) and `+"`run`"+` it *now*.
+Keep well-known words ~~removed~~ and 2*3*4 as is.
+
+- first
+  - nested
+1. numbered
+
+`+"```go"+`
+fmt.Println("*hi*")
+`+"```"+`
+> **Warning**
+> Deploy on Monday
+| Env | Status |
+| --- | --- |
+| prod | up |
+> quoted`, WikiToMarkdown(wikiMarkup))
+}
+
+func TestWikiToText(t *testing.T) {
+	assert.Equal(t, `Context
+Ask @jane.doe about the API, see the docs (https://example.com) and run it now.
+Keep well-known words removed and 2*3*4 as is.
+
+- first
+  - nested
+1. numbered
+
+fmt.Println("*hi*")
+Warning:
+Deploy on Monday
+Env | Status
+prod | up
+quoted`, WikiToText(wikiMarkup))
+}
+
+func TestWikiToMarkdown_InlineBlocks(t *testing.T) {
+	assert.Equal(t, "> quoted\n`code`", WikiToMarkdown("{quote}quoted{quote}\n{noformat}code{noformat}"))
+}
@@ -0,0 +1,115 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+)
+
+// Roles of the chat messages.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message is a chat message.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Options are the generation options of the model, left to the defaults of the model if unset.
+type Options struct {
+	// Temperature is the randomness of the responses, higher being more creative.
+	Temperature *float64 `json:"temperature,omitempty"`
+	// NumCtx is the size of the context window in tokens, only supported by Ollama.
+	NumCtx int `json:"num_ctx,omitempty"`
+	// Seed makes the responses reproducible.
+	Seed *int `json:"seed,omitempty"`
+	// TopP is the cumulative probability of the tokens sampled from.
+	TopP *float64 `json:"top_p,omitempty"`
+	// Stop are the sequences at which the generation stops.
+	Stop []string `json:"stop,omitempty"`
+}
+
+// IsZero reports whether no option is set.
+func (o Options) IsZero() bool {
+	return o.Temperature == nil && o.NumCtx == 0 && o.Seed == nil && o.TopP == nil && len(o.Stop) == 0
+}
+
+// LLM is a large language model provider, such as Ollama or an OpenAI-compatible server.
+// A streamed response is printed as it comes, in which case the generated response is empty.
+type LLM interface {
+	// Generate generates a response to the prompt, the raw prompt bypassing the template of the model if supported.
+	Generate(ctx context.Context, model, prompt string, stream, raw bool) (string, error)
+	// Chat generates the next message of the chat, which holds the whole content even if streamed.
+	Chat(ctx context.Context, model string, messages []Message, stream bool) (Message, error)
+	// Embed returns the embedding of each input.
+	Embed(ctx context.Context, model string, input []string) ([][]float64, error)
+}
+
+// Providers of LLMs.
+const (
+	Ollama = "ollama"
+	OpenAI = "openai"
+)
+
+// Request is a text prompt over context data, such as Jira issues.
+type Request struct {
+	// System is the system prompt, followed by the context if set.
+	System string
+	// Prompt is the text prompt.
+	Prompt string
+	// Context is the context data, such as the Jira issues rendered or summarized.
+	Context string
+	// Templated reports whether the context is a rendered template, which already holds the text prompt.
+	Templated bool
+}
+
+// Content returns the user content of the request: the context, preceded by the text prompt unless templated.
+func (r Request) Content() string {
+	if r.Templated {
+		return r.Context
+	}
+	return fmt.Sprintf("%s\n%s", r.Prompt, r.Context)
+}
+
+// Messages returns the chat messages of the request.
+// With a system prompt, the context goes in the system message and the text prompt is the question,
+// unless the context is templated, in which case the system prompt is followed by the rendered template alone.
+func (r Request) Messages() []Message {
+	switch {
+	case r.System == "":
+		return []Message{{Role: RoleUser, Content: r.Content()}}
+	case r.Templated:
+		return []Message{
+			{Role: RoleSystem, Content: r.System},
+			{Role: RoleUser, Content: r.Context},
+		}
+	default:
+		return []Message{
+			{Role: RoleSystem, Content: fmt.Sprintf("%s\n%s", r.System, r.Context)},
+			{Role: RoleUser, Content: r.Prompt},
+		}
+	}
+}
+
+// Respond generates the response to the request, chatting with a system prompt or else generating from its content,
+// the raw content bypassing the template of the model if supported. A streamed response is printed as it comes,
+// in which case the returned response is empty.
+func Respond(ctx context.Context, l LLM, model string, r Request, stream, raw bool) (string, error) {
+	if r.System == "" {
+		return l.Generate(ctx, model, r.Content(), stream, raw)
+	}
+
+	message, err := l.Chat(ctx, model, r.Messages(), stream)
+	if err != nil || stream {
+		return "", err
+	}
+	return message.Content, nil
+}
+
+// Prompt generates a response to the text prompt followed by the Jira response.
+func Prompt(ctx context.Context, l LLM, model, textPrompt, jiraResponse string, stream, raw bool) (string, error) {
+	return l.Generate(ctx, model, fmt.Sprintf("%s\n%s", textPrompt, jiraResponse), stream, raw)
+}
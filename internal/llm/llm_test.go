@@ -0,0 +1,71 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequest_Messages(t *testing.T) {
+	request := Request{Prompt: "Describe the board:", Context: `{"issues":[]}`}
+	assert.Equal(t, "Describe the board:\n{\"issues\":[]}", request.Content())
+	assert.Equal(t, []Message{{Role: RoleUser, Content: "Describe the board:\n{\"issues\":[]}"}}, request.Messages())
+
+	request.System = "You are a scrum master."
+	assert.Equal(t, []Message{
+		{Role: RoleSystem, Content: "You are a scrum master.\n{\"issues\":[]}"},
+		{Role: RoleUser, Content: "Describe the board:"},
+	}, request.Messages(), "Expected the context in the system message and the text prompt as the question")
+
+	// A rendered template already holds the text prompt, which is not sent again
+	request = Request{Prompt: "Describe the board:", Context: "Describe the board:\n- PROJ-1", Templated: true}
+	assert.Equal(t, "Describe the board:\n- PROJ-1", request.Content())
+	assert.Equal(t, []Message{{Role: RoleUser, Content: "Describe the board:\n- PROJ-1"}}, request.Messages())
+
+	request.System = "You are a scrum master."
+	assert.Equal(t, []Message{
+		{Role: RoleSystem, Content: "You are a scrum master."},
+		{Role: RoleUser, Content: "Describe the board:\n- PROJ-1"},
+	}, request.Messages(), "Expected the system prompt followed by the rendered template alone")
+}
+
+// fakeLLM records how it is prompted.
+type fakeLLM struct {
+	prompt   string
+	raw      bool
+	messages []Message
+}
+
+func (f *fakeLLM) Generate(_ context.Context, _, prompt string, _, raw bool) (string, error) {
+	f.prompt, f.raw = prompt, raw
+	return "generated", nil
+}
+
+func (f *fakeLLM) Chat(_ context.Context, _ string, messages []Message, _ bool) (Message, error) {
+	f.messages = messages
+	return Message{Role: RoleAssistant, Content: "chatted"}, nil
+}
+
+func (f *fakeLLM) Embed(context.Context, string, []string) ([][]float64, error) {
+	return nil, nil
+}
+
+func TestRespond(t *testing.T) {
+	f := &fakeLLM{}
+	res, err := Respond(context.Background(), f, "llama3", Request{Prompt: "Describe the board:", Context: "[]"}, false, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "generated", res)
+	assert.Equal(t, "Describe the board:\n[]", f.prompt)
+	assert.True(t, f.raw)
+
+	request := Request{System: "You are a scrum master.", Prompt: "Describe the board:", Context: "[]"}
+	res, err = Respond(context.Background(), f, "llama3", request, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "chatted", res)
+	assert.Equal(t, request.Messages(), f.messages)
+
+	res, err = Respond(context.Background(), f, "llama3", request, true, false)
+	assert.NoError(t, err)
+	assert.Empty(t, res, "Expected a streamed response to have been printed already")
+}
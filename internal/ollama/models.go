@@ -0,0 +1,186 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrModelNotFound is returned when a model is not available locally.
+var ErrModelNotFound = errors.New("model not found")
+
+// Model is a model available locally.
+type Model struct {
+	Name       string       `json:"name"`
+	Model      string       `json:"model"`
+	ModifiedAt time.Time    `json:"modified_at"`
+	Size       int64        `json:"size"`
+	Digest     string       `json:"digest"`
+	Details    ModelDetails `json:"details"`
+}
+
+// ModelDetails are the format, family, size and quantization of a model.
+type ModelDetails struct {
+	ParentModel       string   `json:"parent_model,omitempty"`
+	Format            string   `json:"format,omitempty"`
+	Family            string   `json:"family,omitempty"`
+	Families          []string `json:"families,omitempty"`
+	ParameterSize     string   `json:"parameter_size,omitempty"`
+	QuantizationLevel string   `json:"quantization_level,omitempty"`
+}
+
+// ModelInfo is the information of a model, such as its template, parameters and architecture.
+type ModelInfo struct {
+	Modelfile    string         `json:"modelfile,omitempty"`
+	Parameters   string         `json:"parameters,omitempty"`
+	Template     string         `json:"template,omitempty"`
+	System       string         `json:"system,omitempty"`
+	License      string         `json:"license,omitempty"`
+	Details      ModelDetails   `json:"details"`
+	ModelInfo    map[string]any `json:"model_info,omitempty"`
+	Capabilities []string       `json:"capabilities,omitempty"`
+	ModifiedAt   time.Time      `json:"modified_at"`
+}
+
+// ContextLength returns the maximum size of the context window of the model in tokens, 0 if unknown.
+func (m *ModelInfo) ContextLength() int {
+	architecture, _ := m.ModelInfo["general.architecture"].(string)
+	contextLength, _ := m.ModelInfo[architecture+".context_length"].(float64)
+	return int(contextLength)
+}
+
+// NumCtx returns the size of the context window of the model in tokens, as set by the num_ctx parameter
+// of its Modelfile, or else its maximum size, 0 if unknown.
+func (m *ModelInfo) NumCtx() int {
+	for _, line := range strings.Split(m.Parameters, "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "num_ctx" {
+			if numCtx, err := strconv.Atoi(fields[1]); err == nil {
+				return numCtx
+			}
+		}
+	}
+	return m.ContextLength()
+}
+
+// PullProgress is the progress of a model pull, whose layers are downloaded one at a time.
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type modelRequest struct {
+	Model  string `json:"model"`
+	Stream bool   `json:"stream"`
+}
+
+type modelsResponse struct {
+	Models []Model `json:"models"`
+}
+
+// Models lists the models available locally.
+func (o *Ollama) Models(ctx context.Context) ([]Model, error) {
+	res, err := o.restClient.R().
+		SetContext(ctx).
+		Get("/api/tags")
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("failed to list models: %s", res.Status())
+	}
+
+	var data modelsResponse
+	if err = json.Unmarshal(res.Body(), &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal models response: %w", err)
+	}
+	return data.Models, nil
+}
+
+// Show returns the information of a model, or ErrModelNotFound if it is not available locally.
+func (o *Ollama) Show(ctx context.Context, model string) (*ModelInfo, error) {
+	res, err := o.restClient.R().
+		SetContext(ctx).
+		SetBody(&modelRequest{Model: model}).
+		Post("/api/show")
+	if err != nil {
+		return nil, err
+	}
+
+	switch res.StatusCode() {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("failed to show %s: %w", model, ErrModelNotFound)
+	default:
+		return nil, fmt.Errorf("failed to show %s: %s", model, res.Status())
+	}
+
+	var info ModelInfo
+	if err = json.Unmarshal(res.Body(), &info); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal show response: %w", err)
+	}
+	return &info, nil
+}
+
+// HasModel reports whether a model is available locally.
+func (o *Ollama) HasModel(ctx context.Context, model string) (bool, error) {
+	_, err := o.Show(ctx, model)
+	if errors.Is(err, ErrModelNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Pull downloads a model, or updates it if already available locally, handling the progress as it is streamed.
+func (o *Ollama) Pull(ctx context.Context, model string, progress func(p PullProgress)) error {
+	zap.S().Infof("📥 Pulling %s model...", model)
+
+	res, err := o.restClient.R().
+		SetContext(ctx).
+		SetDoNotParseResponse(true).
+		SetBody(&modelRequest{Model: model, Stream: true}).
+		Post("/api/pull")
+	if err != nil {
+		return err
+	}
+	defer res.RawBody().Close()
+
+	if res.StatusCode() != http.StatusOK {
+		return fmt.Errorf("failed to pull %s: %s", model, res.Status())
+	}
+
+	decoder := json.NewDecoder(res.RawBody())
+	for {
+		var p PullProgress
+		if err = decoder.Decode(&p); err == io.EOF {
+			return fmt.Errorf("failed to pull %s: the pull ended before succeeding", model)
+		} else if err != nil {
+			if ctx.Err() != nil {
+				return context.Cause(ctx)
+			}
+			return fmt.Errorf("failed to decode pull progress: %w", err)
+		}
+
+		if p.Error != "" {
+			return fmt.Errorf("failed to pull %s: %s", model, p.Error)
+		}
+
+		progress(p)
+
+		if p.Status == "success" {
+			zap.S().Infof("✅ Pull successful!")
+			return nil
+		}
+	}
+}
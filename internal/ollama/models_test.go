@@ -0,0 +1,160 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newModelsServer serves the models of a mock Ollama server, of which llama3:latest is the only one available locally.
+func newModelsServer(t *testing.T, pull string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			assert.Equal(t, http.MethodGet, r.Method)
+			fmt.Fprint(w, `{"models":[{"name":"llama3:latest","model":"llama3:latest","modified_at":"2024-05-01T10:00:00Z","size":4661224676,"digest":"365c0bd3c000","details":{"format":"gguf","family":"llama","parameter_size":"8.0B","quantization_level":"Q4_0"}}]}`)
+		case "/api/show", "/api/generate":
+			var reqBody map[string]interface{}
+			err := json.NewDecoder(r.Body).Decode(&reqBody)
+			assert.NoError(t, err, "Expected to decode request body without error")
+
+			if reqBody["model"] != "llama3" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprintf(w, `{"error":"model '%s' not found"}`, reqBody["model"])
+				return
+			}
+			fmt.Fprint(w, `{"parameters":"stop \"<|eot_id|>\"","details":{"parameter_size":"8.0B","quantization_level":"Q4_0"},"model_info":{"general.architecture":"llama","llama.context_length":8192},"capabilities":["completion"]}`)
+		case "/api/pull":
+			var reqBody map[string]interface{}
+			err := json.NewDecoder(r.Body).Decode(&reqBody)
+			assert.NoError(t, err, "Expected to decode request body without error")
+			assert.Equal(t, true, reqBody["stream"])
+
+			fmt.Fprint(w, pull)
+		default:
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+	}))
+}
+
+func TestModels(t *testing.T) {
+	mockServer := newModelsServer(t, "")
+	defer mockServer.Close()
+
+	models, err := New(mockServer.URL).Models(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, models, 1)
+	assert.Equal(t, "llama3:latest", models[0].Name)
+	assert.Equal(t, int64(4661224676), models[0].Size)
+	assert.Equal(t, "Q4_0", models[0].Details.QuantizationLevel)
+	assert.Equal(t, 2024, models[0].ModifiedAt.Year())
+}
+
+func TestShow(t *testing.T) {
+	mockServer := newModelsServer(t, "")
+	defer mockServer.Close()
+
+	o := New(mockServer.URL)
+	info, err := o.Show(context.Background(), "llama3")
+	assert.NoError(t, err)
+	assert.Equal(t, "8.0B", info.Details.ParameterSize)
+	assert.Equal(t, 8192, info.ContextLength())
+	assert.Equal(t, 8192, info.NumCtx(), "Expected the maximum context length without num_ctx parameter")
+	assert.Equal(t, 16384, (&ModelInfo{Parameters: "num_ctx                        16384\nstop \"<|eot_id|>\""}).NumCtx())
+	assert.Equal(t, []string{"completion"}, info.Capabilities)
+
+	_, err = o.Show(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrModelNotFound)
+
+	found, err := o.HasModel(context.Background(), "llama3")
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	found, err = o.HasModel(context.Background(), "missing")
+	assert.NoError(t, err, "Expected a missing model not to be an error")
+	assert.False(t, found)
+
+	// Prompting a missing model tells it is missing rather than only the status code
+	_, err = o.Generate(context.Background(), "missing", "Rendered prompt", false, false)
+	assert.ErrorIs(t, err, ErrModelNotFound)
+}
+
+func TestPull(t *testing.T) {
+	mockServer := newModelsServer(t, `{"status":"pulling manifest"}
+{"status":"pulling 6a0746a1ec1a","digest":"sha256:6a0746a1ec1a","total":4000}
+{"status":"pulling 6a0746a1ec1a","digest":"sha256:6a0746a1ec1a","total":4000,"completed":4000}
+{"status":"success"}
+`)
+	defer mockServer.Close()
+
+	var progress []PullProgress
+	err := New(mockServer.URL).Pull(context.Background(), "llama3", func(p PullProgress) {
+		progress = append(progress, p)
+	})
+	assert.NoError(t, err)
+	assert.Len(t, progress, 4)
+	assert.Equal(t, int64(4000), progress[2].Completed)
+
+	mockServer = newModelsServer(t, `{"status":"pulling manifest"}
+{"error":"pull model manifest: file does not exist"}
+`)
+	defer mockServer.Close()
+
+	err = New(mockServer.URL).Pull(context.Background(), "missing", func(PullProgress) {})
+	assert.EqualError(t, err, "failed to pull missing: pull model manifest: file does not exist")
+
+	mockServer = newModelsServer(t, `{"status":"pulling manifest"}
+`)
+	defer mockServer.Close()
+
+	err = New(mockServer.URL).Pull(context.Background(), "llama3", func(PullProgress) {})
+	assert.EqualError(t, err, "failed to pull llama3: the pull ended before succeeding")
+}
+
+func TestProgressBar(t *testing.T) {
+	updates := []PullProgress{
+		{Status: "pulling manifest"},
+		{Status: "pulling 6a0746a1ec1a", Total: 4000},
+		{Status: "pulling 6a0746a1ec1a", Total: 4000, Completed: 1000},
+		{Status: "pulling 6a0746a1ec1a", Total: 4000, Completed: 4000},
+		{Status: "verifying sha256 digest"},
+		{Status: "success"},
+	}
+
+	var buf bytes.Buffer
+	p := NewProgressBar(&buf, false)
+	for _, update := range updates {
+		p.Update(update)
+	}
+	p.Done()
+
+	// Without a terminal, each bar is only written once complete
+	assert.Equal(t, `pulling manifest
+pulling 6a0746a1ec1a 100% [==============================] 4.0 kB/4.0 kB
+verifying sha256 digest
+success
+`, buf.String())
+
+	buf.Reset()
+	p = NewProgressBar(&buf, true)
+	for _, update := range updates[:3] {
+		p.Update(update)
+	}
+	p.Done()
+
+	assert.Equal(t, "pulling manifest\n"+
+		"\rpulling 6a0746a1ec1a   0% [                              ] 0 B/4.0 kB\033[K"+
+		"\rpulling 6a0746a1ec1a  25% [=======                       ] 1.0 kB/4.0 kB\033[K\n", buf.String())
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "999 B", FormatSize(999))
+	assert.Equal(t, "1.5 kB", FormatSize(1500))
+	assert.Equal(t, "4.7 GB", FormatSize(4661224676))
+}
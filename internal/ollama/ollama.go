@@ -1,25 +1,69 @@
 package ollama
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/go-resty/resty/v2"
+	"github.com/jhandguy/jira-prompt/internal/llm"
 	"go.uber.org/zap"
 )
 
+// Ollama is the client of an Ollama server.
 type Ollama struct {
 	restClient *resty.Client
+	options    *Options
+	keepAlive  json.RawMessage
+	format     json.RawMessage
 }
 
+var _ llm.LLM = (*Ollama)(nil)
+
+// Roles of the chat messages.
+const (
+	RoleSystem    = llm.RoleSystem
+	RoleUser      = llm.RoleUser
+	RoleAssistant = llm.RoleAssistant
+)
+
+// Message is a chat message.
+type Message = llm.Message
+
+// Options are the generation options of the model, sent as the options of each request.
+type Options = llm.Options
+
 type request struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-	Stream bool   `json:"stream"`
-	Raw    bool   `json:"raw"`
+	Model     string          `json:"model"`
+	Prompt    string          `json:"prompt,omitempty"`
+	Messages  []Message       `json:"messages,omitempty"`
+	Stream    bool            `json:"stream"`
+	Raw       bool            `json:"raw"`
+	Options   *Options        `json:"options,omitempty"`
+	KeepAlive json.RawMessage `json:"keep_alive,omitempty"`
+	Format    json.RawMessage `json:"format,omitempty"`
+}
+
+type embedRequest struct {
+	Model     string          `json:"model"`
+	Input     []string        `json:"input"`
+	Options   *Options        `json:"options,omitempty"`
+	KeepAlive json.RawMessage `json:"keep_alive,omitempty"`
+}
+
+type embedResponse struct {
+	Embeddings [][]float64 `json:"embeddings"`
+}
+
+type chunk struct {
+	Response string  `json:"response"`
+	Message  Message `json:"message"`
+	Done     bool    `json:"done"`
+	Error    string  `json:"error"`
 }
 
 func New(baseURL string) *Ollama {
@@ -31,57 +75,207 @@ func New(baseURL string) *Ollama {
 	}
 }
 
-func (o *Ollama) Prompt(model, textPrompt, jiraResponse string, stream, raw bool) (string, error) {
-	prompt := fmt.Sprintf("%s\n%s", textPrompt, jiraResponse)
+// WithTimeout bounds the time of each request, including reading a streamed response (0 for no timeout).
+func (o *Ollama) WithTimeout(timeout time.Duration) *Ollama {
+	o.restClient.SetTimeout(timeout)
+	return o
+}
+
+// WithOptions sets the generation options of the model, sent with each request unless none is set.
+func (o *Ollama) WithOptions(options Options) *Ollama {
+	o.options = nil
+	if !options.IsZero() {
+		o.options = &options
+	}
+	return o
+}
+
+// WithKeepAlive sets how long the model stays loaded after each request, e.g. 10m, or -1 to keep it loaded
+// (the default of the server if empty).
+// A plain number of seconds, such as -1, is sent as a JSON number, as Ollama only parses durations with a unit.
+func (o *Ollama) WithKeepAlive(keepAlive string) *Ollama {
+	o.keepAlive = nil
+	if seconds, err := strconv.Atoi(keepAlive); err == nil {
+		o.keepAlive = json.RawMessage(strconv.Itoa(seconds))
+	} else if keepAlive != "" {
+		o.keepAlive, _ = json.Marshal(keepAlive)
+	}
+	return o
+}
+
+// WithFormat constrains the responses to the format, either "json" or a JSON schema (unconstrained if nil).
+func (o *Ollama) WithFormat(format json.RawMessage) *Ollama {
+	o.format = format
+	return o
+}
+
+// Prompt generates a response to the text prompt followed by the Jira response.
+func (o *Ollama) Prompt(ctx context.Context, model, textPrompt, jiraResponse string, stream, raw bool) (string, error) {
+	return llm.Prompt(ctx, o, model, textPrompt, jiraResponse, stream, raw)
+}
+
+// Generate generates a response to the prompt, which is printed as it comes if streamed.
+func (o *Ollama) Generate(ctx context.Context, model, prompt string, stream, raw bool) (string, error) {
 	zap.S().Infof("💬 Prompting %s model...", model)
 	zap.S().Debug(prompt)
 
-	res, err := o.restClient.R().
-		SetDoNotParseResponse(stream).
-		SetBody(&request{
-			Model:  model,
-			Prompt: prompt,
-			Stream: stream,
-			Raw:    raw,
-		}).
-		Post("/api/generate")
+	res, err := o.post(ctx, "/api/generate", &request{
+		Model:  model,
+		Prompt: prompt,
+		Stream: stream,
+		Raw:    raw,
+	})
 	if err != nil {
 		return "", err
 	}
 	defer res.RawBody().Close()
 
+	if !stream {
+		return unmarshallResponse([]byte(res.String()))
+	}
+
+	if err = decodeStream(res.RawBody(), func(data chunk) {
+		fmt.Print(data.Response)
+	}); err != nil {
+		return "", streamError(ctx, err)
+	}
+
+	return "", nil
+}
+
+// Chat generates the next message of the chat, whose content is printed as it comes if streamed.
+func (o *Ollama) Chat(ctx context.Context, model string, messages []Message, stream bool) (Message, error) {
+	zap.S().Infof("💬 Chatting with %s model...", model)
+	for _, message := range messages {
+		zap.S().Debugf("%s: %s", message.Role, message.Content)
+	}
+
+	res, err := o.post(ctx, "/api/chat", &request{
+		Model:    model,
+		Messages: messages,
+		Stream:   stream,
+	})
+	if err != nil {
+		return Message{}, err
+	}
+	defer res.RawBody().Close()
+
+	if !stream {
+		var data chunk
+		if err = json.Unmarshal(res.Body(), &data); err != nil {
+			return Message{}, fmt.Errorf("failed unmarshal chat response: %w", err)
+		}
+		if data.Error != "" {
+			return Message{}, fmt.Errorf("failed to generate response: %s", data.Error)
+		}
+		if data.Message.Role == "" {
+			return Message{}, fmt.Errorf("the \"message\" field is missing in the returned JSON: %s", res.Body())
+		}
+		return data.Message, nil
+	}
+
+	message := Message{Role: RoleAssistant}
+	if err = decodeStream(res.RawBody(), func(data chunk) {
+		fmt.Print(data.Message.Content)
+		message.Content += data.Message.Content
+	}); err != nil {
+		return message, streamError(ctx, err)
+	}
+
+	return message, nil
+}
+
+// Embed returns the embedding of each input.
+func (o *Ollama) Embed(ctx context.Context, model string, input []string) ([][]float64, error) {
+	zap.S().Debugf("🧮 Embedding %d inputs with %s model...", len(input), model)
+
+	res, err := o.restClient.R().
+		SetContext(ctx).
+		SetBody(&embedRequest{
+			Model:     model,
+			Input:     input,
+			Options:   o.options,
+			KeepAlive: o.keepAlive,
+		}).
+		Post("/api/embed")
+	if err != nil {
+		return nil, err
+	}
+
 	if res.StatusCode() != http.StatusOK {
-		return "", fmt.Errorf("failed to prompt %s: %s", model, res.Status())
+		return nil, fmt.Errorf("failed to embed with %s: %s", model, res.Status())
+	}
+
+	var data embedResponse
+	if err = json.Unmarshal(res.Body(), &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal embed response: %w", err)
+	}
+
+	if len(data.Embeddings) != len(input) {
+		return nil, fmt.Errorf("failed to embed with %s: got %d embeddings for %d inputs", model, len(data.Embeddings), len(input))
+	}
+	return data.Embeddings, nil
+}
+
+func (o *Ollama) post(ctx context.Context, path string, body *request) (*resty.Response, error) {
+	body.Options = o.options
+	body.KeepAlive = o.keepAlive
+	body.Format = o.format
+
+	res, err := o.restClient.R().
+		SetContext(ctx).
+		SetDoNotParseResponse(body.Stream).
+		SetBody(body).
+		Post(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch res.StatusCode() {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		res.RawBody().Close()
+		return nil, fmt.Errorf("failed to prompt %s: %w", body.Model, ErrModelNotFound)
+	default:
+		res.RawBody().Close()
+		return nil, fmt.Errorf("failed to prompt %s: %s", body.Model, res.Status())
 	}
 
 	zap.S().Infof("✅ Prompt successful!")
+	return res, nil
+}
 
-	if !stream {
-		return unmarshallResponse([]byte(res.String()))
+// streamError returns the cause of a stream interruption, such as its context being canceled.
+func streamError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
 	}
+	return err
+}
 
-	buf := make([]byte, 10240)
+// decodeStream decodes the newline delimited JSON objects streamed by Ollama,
+// regardless of how they were split or coalesced by the reads, until the final one.
+func decodeStream(body io.Reader, handle func(data chunk)) error {
+	decoder := json.NewDecoder(body)
 	for {
-		n, readErr := res.RawBody().Read(buf)
-		if readErr == io.EOF {
-			// We've read all the data
-			break
-		}
-		if readErr != nil {
-			log.Fatalf("error reading from stream: %v", readErr)
+		var data chunk
+		if err := decoder.Decode(&data); err == io.EOF {
+			// The stream ended without a final chunk, e.g. as the connection dropped
+			return fmt.Errorf("failed to decode streamed response: %w", io.ErrUnexpectedEOF)
+		} else if err != nil {
+			return fmt.Errorf("failed to decode streamed response: %w", err)
 		}
 
-		// Process the chunk that was read
-		chunk := buf[:n]
-		resp, err := unmarshallResponse(chunk)
-		if err != nil {
-			return "", err
+		if data.Error != "" {
+			return fmt.Errorf("failed to generate response: %s", data.Error)
 		}
 
-		fmt.Print(resp)
-	}
+		handle(data)
 
-	return "", nil
+		if data.Done {
+			return nil
+		}
+	}
 }
 
 func unmarshallResponse(response []byte) (string, error) {
@@ -2,13 +2,16 @@ package ollama
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"testing/iotest"
 	"time"
 
 	"github.com/stretchr/testify/assert"
@@ -47,7 +50,7 @@ func TestPrompt_Success(t *testing.T) {
 	model := "test-model"
 	textPrompt := "Hello from text"
 	jiraResponse := "Some JIRA data"
-	resp, err := o.Prompt(model, textPrompt, jiraResponse, false, false)
+	resp, err := o.Prompt(context.Background(), model, textPrompt, jiraResponse, false, false)
 
 	// Assertions
 	assert.NoError(t, err, "Expected no error on successful call")
@@ -64,7 +67,7 @@ func TestPrompt_NonOK(t *testing.T) {
 
 	o := New(mockServer.URL)
 
-	_, err := o.Prompt("failing-model", "any prompt", "any Jira data", false, false)
+	_, err := o.Prompt(context.Background(), "failing-model", "any prompt", "any Jira data", false, false)
 	assert.Error(t, err, "Expected error for non-200 response")
 	assert.Contains(t, err.Error(), "failed to prompt failing-model: 400 Bad Request")
 }
@@ -79,7 +82,7 @@ func TestPrompt_InvalidJSON(t *testing.T) {
 
 	o := New(mockServer.URL)
 
-	_, err := o.Prompt("test-model", "text prompt", "jira data", false, false)
+	_, err := o.Prompt(context.Background(), "test-model", "text prompt", "jira data", false, false)
 	assert.Error(t, err, "Expected JSON unmarshal error")
 	assert.Contains(t, err.Error(), "failed unmarshal generated response", "Error message should mention unmarshal")
 }
@@ -115,7 +118,7 @@ func TestPrompt_ResponseFieldIsMissingOrWrongType(t *testing.T) {
 			o := New(mockServer.URL)
 
 			// Call Prompt; expect an error about the "response" field
-			_, err := o.Prompt("test-model", "prompt", "jira data", false, false)
+			_, err := o.Prompt(context.Background(), "test-model", "prompt", "jira data", false, false)
 			assert.Error(t, err, "Expected an error due to a missing or invalid 'response' field")
 			assert.Contains(t, err.Error(), `the "response" field is missing or not a string`,
 				"Error message should mention the missing/invalid response field")
@@ -134,7 +137,7 @@ func TestPrompt_Stream(t *testing.T) {
 			raw:  false,
 			chunkParts: []string{
 				`{"response":"Chunk #1 - raw=false"}`,
-				`{"response":"Chunk #2 - raw=false"}`,
+				`{"response":"Chunk #2 - raw=false","done":true}`,
 			},
 		},
 		{
@@ -142,7 +145,7 @@ func TestPrompt_Stream(t *testing.T) {
 			raw:  true,
 			chunkParts: []string{
 				`{"response":"Chunk #1 - raw=true"}`,
-				`{"response":"Chunk #2 - raw=true"}`,
+				`{"response":"Chunk #2 - raw=true","done":true}`,
 			},
 		},
 	}
@@ -192,7 +195,7 @@ func TestPrompt_Stream(t *testing.T) {
 			os.Stdout = wPipe
 
 			// Call the Prompt method in streaming mode
-			result, err := client.Prompt("stream-model", "stream prompt", "jira data", true, tc.raw)
+			result, err := client.Prompt(context.Background(), "stream-model", "stream prompt", "jira data", true, tc.raw)
 
 			// Close and restore stdout
 			wPipe.Close()
@@ -221,3 +224,362 @@ func TestPrompt_Stream(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeStream(t *testing.T) {
+	testCases := []struct {
+		name     string
+		body     io.Reader
+		expected []string
+	}{
+		{
+			name:     "oneChunkPerLine",
+			body:     strings.NewReader("{\"response\":\"Hello\"}\n{\"response\":\" world\"}\n{\"response\":\"\",\"done\":true}\n"),
+			expected: []string{"Hello", " world", ""},
+		},
+		{
+			// Reading one byte at a time splits every chunk across many reads
+			name:     "splitChunks",
+			body:     iotest.OneByteReader(strings.NewReader("{\"response\":\"Hello\"}\n{\"response\":\" world\",\"done\":true}\n")),
+			expected: []string{"Hello", " world"},
+		},
+		{
+			// Several chunks in a single read
+			name:     "coalescedChunks",
+			body:     strings.NewReader(`{"response":"Hello"}{"response":" world"}` + "\n" + `{"response":"!","done":true}`),
+			expected: []string{"Hello", " world", "!"},
+		},
+		{
+			// Nothing after the final chunk should be decoded
+			name:     "stopOnDone",
+			body:     strings.NewReader("{\"response\":\"Hello\",\"done\":true}\ninvalid-json"),
+			expected: []string{"Hello"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var responses []string
+			err := decodeStream(tc.body, func(data chunk) {
+				responses = append(responses, data.Response)
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, responses, "Expected every streamed chunk to be decoded in order")
+		})
+	}
+}
+
+func TestDecodeStream_Errors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		body          string
+		expectedError string
+	}{
+		{
+			name:          "errorChunk",
+			body:          "{\"response\":\"Hello\"}\n{\"error\":\"model requires more system memory\"}\n",
+			expectedError: "failed to generate response: model requires more system memory",
+		},
+		{
+			name:          "invalidChunk",
+			body:          "{\"response\":\"Hello\"}\ninvalid-json\n",
+			expectedError: "failed to decode streamed response",
+		},
+		{
+			name:          "truncatedChunk",
+			body:          "{\"response\":\"Hello\"}\n{\"response\":\"wor",
+			expectedError: "failed to decode streamed response",
+		},
+		{
+			// The connection dropped before the final chunk
+			name:          "missingDone",
+			body:          "{\"response\":\"Hello\"}\n{\"response\":\" wor\"}\n",
+			expectedError: "failed to decode streamed response: unexpected EOF",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := decodeStream(strings.NewReader(tc.body), func(chunk) {})
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tc.expectedError)
+		})
+	}
+}
+
+func TestPrompt_StreamError(t *testing.T) {
+	// Mock server streams an error chunk after a successful one
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, `{"response":"Partial"}`)
+		fmt.Fprintln(w, `{"error":"unexpected server error"}`)
+	}))
+	defer mockServer.Close()
+
+	// Discard stdout, since streaming prints directly to stdout
+	oldStdout := os.Stdout
+	os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	defer func() {
+		os.Stdout.Close()
+		os.Stdout = oldStdout
+	}()
+
+	o := New(mockServer.URL)
+
+	_, err := o.Prompt(context.Background(), "stream-model", "stream prompt", "jira data", true, false)
+	assert.Error(t, err, "Expected the streamed error to be returned")
+	assert.Contains(t, err.Error(), "failed to generate response: unexpected server error")
+}
+
+func TestGenerate_Success(t *testing.T) {
+	// Mock server checks that the prompt is sent as is
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&reqBody)
+		assert.NoError(t, err, "Expected to decode request body without error")
+		assert.Equal(t, "Rendered prompt", reqBody["prompt"], "prompt should not be altered")
+
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"response":"Model output response"}`)
+	}))
+	defer mockServer.Close()
+
+	o := New(mockServer.URL)
+
+	resp, err := o.Generate(context.Background(), "test-model", "Rendered prompt", false, false)
+	assert.NoError(t, err, "Expected no error on successful call")
+	assert.Equal(t, "Model output response", resp)
+}
+
+func TestChat_Success(t *testing.T) {
+	// Mock server to simulate /api/chat endpoint
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/chat", r.URL.Path)
+
+		// The request should follow the chat schema
+		var reqBody map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&reqBody)
+		assert.NoError(t, err, "Expected to decode request body without error")
+		assert.Equal(t, "test-model", reqBody["model"], "model should match")
+		assert.NotContains(t, reqBody, "prompt", "prompt should not be sent to the chat endpoint")
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"role": "system", "content": "Some JIRA data"},
+			map[string]interface{}{"role": "user", "content": "What is the team working on?"},
+			map[string]interface{}{"role": "assistant", "content": "On PROJ-1."},
+			map[string]interface{}{"role": "user", "content": "Who is assigned?"},
+		}, reqBody["messages"], "messages should be sent in order")
+
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"message":{"role":"assistant","content":"Jane Doe."},"done":true}`)
+	}))
+	defer mockServer.Close()
+
+	o := New(mockServer.URL)
+
+	message, err := o.Chat(context.Background(), "test-model", []Message{
+		{Role: RoleSystem, Content: "Some JIRA data"},
+		{Role: RoleUser, Content: "What is the team working on?"},
+		{Role: RoleAssistant, Content: "On PROJ-1."},
+		{Role: RoleUser, Content: "Who is assigned?"},
+	}, false)
+	assert.NoError(t, err, "Expected no error on successful call")
+	assert.Equal(t, Message{Role: RoleAssistant, Content: "Jane Doe."}, message)
+}
+
+func TestChat_Stream(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":"Jane"},"done":false}`)
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":" Doe."},"done":false}`)
+		fmt.Fprintln(w, `{"message":{"role":"assistant","content":""},"done":true}`)
+	}))
+	defer mockServer.Close()
+
+	// Capture stdout, since streaming prints directly to stdout
+	oldStdout := os.Stdout
+	r, wPipe, _ := os.Pipe()
+	os.Stdout = wPipe
+
+	o := New(mockServer.URL)
+	message, err := o.Chat(context.Background(), "stream-model", []Message{{Role: RoleUser, Content: "Who is assigned?"}}, true)
+
+	wPipe.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+
+	// The streamed message is both printed and returned, to be kept in the chat history
+	assert.NoError(t, err)
+	assert.Equal(t, "Jane Doe.", buf.String())
+	assert.Equal(t, Message{Role: RoleAssistant, Content: "Jane Doe."}, message)
+}
+
+func TestChat_MessageIsMissing(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, `{"done":true}`)
+	}))
+	defer mockServer.Close()
+
+	o := New(mockServer.URL)
+
+	_, err := o.Chat(context.Background(), "test-model", []Message{{Role: RoleUser, Content: "Hello"}}, false)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `the "message" field is missing`)
+}
+
+func TestGenerate_StreamCanceled(t *testing.T) {
+	// Mock server streams a first chunk, then hangs until the client goes away
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, `{"response":"Partial"}`)
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer mockServer.Close()
+
+	// Capture stdout, since streaming prints directly to stdout
+	oldStdout := os.Stdout
+	r, wPipe, _ := os.Pipe()
+	os.Stdout = wPipe
+
+	// Cancel the context once the first chunk had time to arrive
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	o := New(mockServer.URL)
+	_, err := o.Generate(ctx, "stream-model", "stream prompt", true, false)
+
+	wPipe.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+
+	// The partial output is printed and the cancellation is reported as such
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "Partial", buf.String())
+}
+
+func TestGenerate_Timeout(t *testing.T) {
+	// Mock server takes longer to respond than the timeout
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, `{"response":"Too late"}`)
+	}))
+	defer mockServer.Close()
+
+	o := New(mockServer.URL).WithTimeout(50 * time.Millisecond)
+
+	start := time.Now()
+	_, err := o.Generate(context.Background(), "slow-model", "prompt", false, false)
+	assert.Error(t, err, "Expected the request to time out")
+	assert.Less(t, time.Since(start), time.Second, "Expected the request to be interrupted")
+}
+
+func TestEmbed(t *testing.T) {
+	var inputs []interface{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/embed", r.URL.Path)
+
+		var reqBody map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&reqBody)
+		assert.NoError(t, err, "Expected to decode request body without error")
+		assert.Equal(t, "embed-model", reqBody["model"], "model should match")
+		inputs = append(inputs, reqBody["input"])
+
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"model":"embed-model","embeddings":[[0.1,0.2],[0.3,0.4]]}`)
+	}))
+	defer mockServer.Close()
+
+	o := New(mockServer.URL)
+
+	embeddings, err := o.Embed(context.Background(), "embed-model", []string{"PROJ-1", "PROJ-2"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]float64{{0.1, 0.2}, {0.3, 0.4}}, embeddings)
+
+	// An embedding per input is expected
+	_, err = o.Embed(context.Background(), "embed-model", []string{"PROJ-1"})
+	assert.EqualError(t, err, "failed to embed with embed-model: got 2 embeddings for 1 inputs")
+
+	assert.Equal(t, []interface{}{[]interface{}{"PROJ-1", "PROJ-2"}, []interface{}{"PROJ-1"}}, inputs, "inputs should be sent in order")
+}
+
+func TestGenerate_Options(t *testing.T) {
+	var requests []map[string]interface{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&reqBody)
+		assert.NoError(t, err, "Expected to decode request body without error")
+		requests = append(requests, reqBody)
+
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"response":"Model output response","message":{"role":"assistant","content":"Jane Doe."}}`)
+	}))
+	defer mockServer.Close()
+
+	temperature, seed := 0.2, 42
+	o := New(mockServer.URL).
+		WithOptions(Options{Temperature: &temperature, NumCtx: 8192, Seed: &seed, Stop: []string{"\n\n"}}).
+		WithKeepAlive("10m")
+
+	_, err := o.Generate(context.Background(), "test-model", "Rendered prompt", false, false)
+	assert.NoError(t, err)
+
+	_, err = o.Chat(context.Background(), "test-model", []Message{{Role: RoleUser, Content: "Who is assigned?"}}, false)
+	assert.NoError(t, err)
+
+	// Unset options are left to the defaults of the model
+	for _, reqBody := range requests {
+		assert.Equal(t, map[string]interface{}{"temperature": 0.2, "num_ctx": 8192.0, "seed": 42.0, "stop": []interface{}{"\n\n"}}, reqBody["options"])
+		assert.Equal(t, "10m", reqBody["keep_alive"])
+	}
+
+	// Without any option, none is sent
+	requests = nil
+	_, err = o.WithOptions(Options{}).WithKeepAlive("").Generate(context.Background(), "test-model", "Rendered prompt", false, false)
+	assert.NoError(t, err)
+	assert.NotContains(t, requests[0], "options")
+	assert.NotContains(t, requests[0], "keep_alive")
+
+	// A number of seconds is sent as a number
+	requests = nil
+	_, err = o.WithKeepAlive("-1").Generate(context.Background(), "test-model", "Rendered prompt", false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, -1.0, requests[0]["keep_alive"])
+}
+
+func TestChat_Format(t *testing.T) {
+	var requests []map[string]interface{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&reqBody)
+		assert.NoError(t, err, "Expected to decode request body without error")
+		requests = append(requests, reqBody)
+
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, `{"message":{"role":"assistant","content":"{\"key\":\"PROJ-1\"}"}}`)
+	}))
+	defer mockServer.Close()
+
+	o := New(mockServer.URL).WithFormat(json.RawMessage(`"json"`))
+	_, err := o.Chat(context.Background(), "test-model", []Message{{Role: RoleUser, Content: "Which issue?"}}, false)
+	assert.NoError(t, err)
+
+	_, err = o.WithFormat(json.RawMessage(`{"type":"object"}`)).Chat(context.Background(), "test-model", []Message{{Role: RoleUser, Content: "Which issue?"}}, false)
+	assert.NoError(t, err)
+
+	_, err = o.WithFormat(nil).Chat(context.Background(), "test-model", []Message{{Role: RoleUser, Content: "Which issue?"}}, false)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "json", requests[0]["format"])
+	assert.Equal(t, map[string]interface{}{"type": "object"}, requests[1]["format"])
+	assert.NotContains(t, requests[2], "format", "Unconstrained responses should send no format")
+}
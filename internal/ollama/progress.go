@@ -0,0 +1,90 @@
+package ollama
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// progressBarWidth is the number of characters of a progress bar.
+const progressBarWidth = 30
+
+// ProgressBar writes the progress of a model pull, with a bar for each downloaded layer.
+// An interactive bar is redrawn in place as the layer downloads, while any other is only written once complete.
+type ProgressBar struct {
+	w           io.Writer
+	interactive bool
+	// status is the status of the last progress written, whose bar is pending if any.
+	status string
+	bar    string
+}
+
+// NewProgressBar creates the progress bar of a model pull, redrawn in place if interactive, e.g. in a terminal.
+func NewProgressBar(w io.Writer, interactive bool) *ProgressBar {
+	return &ProgressBar{w: w, interactive: interactive}
+}
+
+// Update writes the progress, as a bar if the total size of the layer being downloaded is known.
+func (p *ProgressBar) Update(progress PullProgress) {
+	if progress.Status != p.status {
+		p.Done()
+		p.status = progress.Status
+		if progress.Total == 0 {
+			fmt.Fprintln(p.w, progress.Status)
+			return
+		}
+	}
+
+	if progress.Total == 0 {
+		return
+	}
+
+	p.bar = formatBar(progress)
+	if p.interactive {
+		// The bar is redrawn over the previous one
+		fmt.Fprintf(p.w, "\r%s\033[K", p.bar)
+	}
+}
+
+// Done ends the pending bar, if any.
+func (p *ProgressBar) Done() {
+	if p.bar == "" {
+		return
+	}
+
+	if p.interactive {
+		fmt.Fprintln(p.w)
+	} else {
+		fmt.Fprintln(p.w, p.bar)
+	}
+	p.bar = ""
+}
+
+// formatBar formats the progress of a layer, e.g. pulling 6a0746a1ec1a  42% [============                  ] 1.9 GB/4.7 GB.
+func formatBar(progress PullProgress) string {
+	completed := min(max(progress.Completed, 0), progress.Total)
+	filled := int(completed * progressBarWidth / progress.Total)
+	return fmt.Sprintf("%s %3d%% [%s%s] %s/%s",
+		progress.Status,
+		completed*100/progress.Total,
+		strings.Repeat("=", filled),
+		strings.Repeat(" ", progressBarWidth-filled),
+		FormatSize(completed),
+		FormatSize(progress.Total),
+	)
+}
+
+// FormatSize formats a size in bytes with decimal units, e.g. 4.7 GB.
+func FormatSize(size int64) string {
+	const unit = 1000
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "kMGTPE"[exp])
+}
@@ -0,0 +1,356 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/jhandguy/jira-prompt/internal/llm"
+	"go.uber.org/zap"
+)
+
+// OpenAI is the client of an OpenAI-compatible server, such as llama.cpp server, vLLM or LocalAI.
+type OpenAI struct {
+	restClient *resty.Client
+	options    llm.Options
+	format     *responseFormat
+}
+
+var _ llm.LLM = (*OpenAI)(nil)
+
+type chatRequest struct {
+	Model    string        `json:"model"`
+	Messages []llm.Message `json:"messages"`
+	Stream   bool          `json:"stream"`
+	samplingOptions
+	ResponseFormat *responseFormat `json:"response_format,omitempty"`
+}
+
+type completionRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt"`
+	Stream bool   `json:"stream"`
+	samplingOptions
+}
+
+// samplingOptions are the generation options supported by the completions endpoints.
+type samplingOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+}
+
+// responseFormat constrains the chat completions to JSON, matching the JSON schema if any.
+type responseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *jsonSchema `json:"json_schema,omitempty"`
+}
+
+type jsonSchema struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+}
+
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// response is a response, or a streamed chunk of a response, of the completions endpoints.
+type response struct {
+	Choices []choice  `json:"choices"`
+	Error   *apiError `json:"error"`
+}
+
+type choice struct {
+	// Message is the message of a chat completion.
+	Message llm.Message `json:"message"`
+	// Delta is the next part of the message of a streamed chat completion.
+	Delta llm.Message `json:"delta"`
+	// Text is the text of a completion.
+	Text string `json:"text"`
+	// FinishReason is why the completion stopped, only set on the last choice of a stream.
+	FinishReason string `json:"finish_reason"`
+}
+
+type embeddingResponse struct {
+	Data  []embedding `json:"data"`
+	Error *apiError   `json:"error"`
+}
+
+type embedding struct {
+	Index     int       `json:"index"`
+	Embedding []float64 `json:"embedding"`
+}
+
+type apiError struct {
+	Message string `json:"message"`
+}
+
+// New creates the client of the server at the base URL, without the /v1 path, authenticated with the API key if any.
+func New(baseURL, apiKey string) *OpenAI {
+	restClient := resty.
+		New().
+		SetBaseURL(strings.TrimSuffix(strings.TrimSuffix(baseURL, "/"), "/v1")).
+		SetHeader("Content-Type", "application/json")
+	if apiKey != "" {
+		restClient.SetAuthToken(apiKey)
+	}
+	return &OpenAI{restClient: restClient}
+}
+
+// WithTimeout bounds the time of each request, including reading a streamed response (0 for no timeout).
+func (o *OpenAI) WithTimeout(timeout time.Duration) *OpenAI {
+	o.restClient.SetTimeout(timeout)
+	return o
+}
+
+// WithOptions sets the generation options of the model, sent with each completion request.
+// The size of the context window is left to the server, which sets it when loading the model.
+func (o *OpenAI) WithOptions(options llm.Options) *OpenAI {
+	o.options = options
+	return o
+}
+
+// WithFormat constrains the chat completions to the format, either "json" or a JSON schema (unconstrained if nil).
+// Raw completions are left unconstrained, as the completions endpoint has no response format.
+func (o *OpenAI) WithFormat(format json.RawMessage) *OpenAI {
+	switch {
+	case format == nil:
+		o.format = nil
+	case string(format) == `"json"`:
+		o.format = &responseFormat{Type: "json_object"}
+	default:
+		o.format = &responseFormat{Type: "json_schema", JSONSchema: &jsonSchema{Name: "response", Schema: format}}
+	}
+	return o
+}
+
+func (o *OpenAI) samplingOptions() samplingOptions {
+	return samplingOptions{
+		Temperature: o.options.Temperature,
+		Seed:        o.options.Seed,
+		TopP:        o.options.TopP,
+		Stop:        o.options.Stop,
+	}
+}
+
+// Generate generates a response to the prompt, which is printed as it comes if streamed.
+// A raw prompt is completed as is, any other is sent as a user message so that the chat template of the model applies.
+func (o *OpenAI) Generate(ctx context.Context, model, prompt string, stream, raw bool) (string, error) {
+	if !raw {
+		message, err := o.Chat(ctx, model, []llm.Message{{Role: llm.RoleUser, Content: prompt}}, stream)
+		if err != nil || stream {
+			return "", err
+		}
+		return message.Content, nil
+	}
+
+	zap.S().Infof("💬 Prompting %s model...", model)
+	zap.S().Debug(prompt)
+
+	res, err := o.post(ctx, "/v1/completions", model, stream, &completionRequest{
+		Model:           model,
+		Prompt:          prompt,
+		Stream:          stream,
+		samplingOptions: o.samplingOptions(),
+	})
+	if err != nil {
+		return "", err
+	}
+	defer res.RawBody().Close()
+
+	if !stream {
+		c, err := unmarshalChoice(res.Body())
+		if err != nil {
+			return "", err
+		}
+		return c.Text, nil
+	}
+
+	if err = decodeStream(res.RawBody(), func(c choice) {
+		fmt.Print(c.Text)
+	}); err != nil {
+		return "", streamError(ctx, err)
+	}
+
+	return "", nil
+}
+
+// Chat generates the next message of the chat, whose content is printed as it comes if streamed.
+func (o *OpenAI) Chat(ctx context.Context, model string, messages []llm.Message, stream bool) (llm.Message, error) {
+	zap.S().Infof("💬 Chatting with %s model...", model)
+	for _, message := range messages {
+		zap.S().Debugf("%s: %s", message.Role, message.Content)
+	}
+
+	res, err := o.post(ctx, "/v1/chat/completions", model, stream, &chatRequest{
+		Model:           model,
+		Messages:        messages,
+		Stream:          stream,
+		samplingOptions: o.samplingOptions(),
+		ResponseFormat:  o.format,
+	})
+	if err != nil {
+		return llm.Message{}, err
+	}
+	defer res.RawBody().Close()
+
+	if !stream {
+		c, err := unmarshalChoice(res.Body())
+		if err != nil {
+			return llm.Message{}, err
+		}
+		if c.Message.Role == "" {
+			return llm.Message{}, fmt.Errorf("the \"message\" field is missing in the returned JSON: %s", res.Body())
+		}
+		return c.Message, nil
+	}
+
+	message := llm.Message{Role: llm.RoleAssistant}
+	if err = decodeStream(res.RawBody(), func(c choice) {
+		fmt.Print(c.Delta.Content)
+		message.Content += c.Delta.Content
+	}); err != nil {
+		return message, streamError(ctx, err)
+	}
+
+	return message, nil
+}
+
+// Embed returns the embedding of each input.
+func (o *OpenAI) Embed(ctx context.Context, model string, input []string) ([][]float64, error) {
+	zap.S().Debugf("🧮 Embedding %d inputs with %s model...", len(input), model)
+
+	res, err := o.restClient.R().
+		SetContext(ctx).
+		SetBody(&embeddingRequest{
+			Model: model,
+			Input: input,
+		}).
+		Post("/v1/embeddings")
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("failed to embed with %s: %s", model, res.Status())
+	}
+
+	var data embeddingResponse
+	if err = json.Unmarshal(res.Body(), &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal embeddings response: %w", err)
+	}
+	if data.Error != nil {
+		return nil, fmt.Errorf("failed to embed with %s: %s", model, data.Error.Message)
+	}
+
+	if len(data.Data) != len(input) {
+		return nil, fmt.Errorf("failed to embed with %s: got %d embeddings for %d inputs", model, len(data.Data), len(input))
+	}
+
+	// The embeddings are in the order of the inputs, whichever order they are returned in
+	slices.SortFunc(data.Data, func(a, b embedding) int {
+		return a.Index - b.Index
+	})
+
+	embeddings := make([][]float64, len(data.Data))
+	for i, d := range data.Data {
+		embeddings[i] = d.Embedding
+	}
+	return embeddings, nil
+}
+
+func (o *OpenAI) post(ctx context.Context, path, model string, stream bool, body any) (*resty.Response, error) {
+	res, err := o.restClient.R().
+		SetContext(ctx).
+		SetDoNotParseResponse(stream).
+		SetBody(body).
+		Post(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode() != http.StatusOK {
+		res.RawBody().Close()
+		return nil, fmt.Errorf("failed to prompt %s: %s", model, res.Status())
+	}
+
+	zap.S().Infof("✅ Prompt successful!")
+	return res, nil
+}
+
+// unmarshalChoice returns the first choice of a response.
+func unmarshalChoice(body []byte) (choice, error) {
+	var data response
+	if err := json.Unmarshal(body, &data); err != nil {
+		return choice{}, fmt.Errorf("failed unmarshal generated response: %w", err)
+	}
+	if data.Error != nil {
+		return choice{}, fmt.Errorf("failed to generate response: %s", data.Error.Message)
+	}
+	if len(data.Choices) == 0 {
+		return choice{}, fmt.Errorf("the \"choices\" field is missing or empty in the returned JSON: %s", body)
+	}
+	return data.Choices[0], nil
+}
+
+// streamError returns the cause of a stream interruption, such as its context being canceled.
+func streamError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	return err
+}
+
+// decodeStream decodes the server-sent events streamed by the server, each holding a chunk of the response,
+// until the final [DONE] one.
+func decodeStream(body io.Reader, handle func(c choice)) error {
+	var finished bool
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), 1024*1024)
+	for scanner.Scan() {
+		data, ok := bytes.CutPrefix(scanner.Bytes(), []byte("data:"))
+		if !ok {
+			// Blank lines separate the events, which may also hold comments
+			continue
+		}
+
+		data = bytes.TrimSpace(data)
+		if string(data) == "[DONE]" {
+			return nil
+		}
+
+		var chunk response
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return fmt.Errorf("failed to decode streamed response: %w", err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("failed to generate response: %s", chunk.Error.Message)
+		}
+
+		for _, c := range chunk.Choices {
+			handle(c)
+			finished = finished || c.FinishReason != ""
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to decode streamed response: %w", err)
+	}
+
+	// The stream ended without a final event, which is fine once the completion finished
+	if !finished {
+		return fmt.Errorf("failed to decode streamed response: %w", io.ErrUnexpectedEOF)
+	}
+	return nil
+}
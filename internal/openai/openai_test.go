@@ -0,0 +1,248 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/jhandguy/jira-prompt/internal/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+// captureStdout returns what f prints to stdout, since streaming prints directly to stdout.
+func captureStdout(t *testing.T, f func()) string {
+	oldStdout := os.Stdout
+	r, w, err := os.Pipe()
+	assert.NoError(t, err)
+	os.Stdout = w
+
+	f()
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	_, _ = io.Copy(&buf, r)
+	return buf.String()
+}
+
+func TestChat_Success(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer test-api-key", r.Header.Get("Authorization"))
+
+		var reqBody map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&reqBody)
+		assert.NoError(t, err, "Expected to decode request body without error")
+		assert.Equal(t, "test-model", reqBody["model"], "model should match")
+		assert.Equal(t, false, reqBody["stream"], "stream should be false")
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"role": "system", "content": "Some JIRA data"},
+			map[string]interface{}{"role": "user", "content": "Who is assigned?"},
+		}, reqBody["messages"], "messages should be sent in order")
+
+		fmt.Fprint(w, `{"id":"chatcmpl-1","choices":[{"index":0,"message":{"role":"assistant","content":"Jane Doe."},"finish_reason":"stop"}]}`)
+	}))
+	defer mockServer.Close()
+
+	// The /v1 path of the base URL is optional
+	o := New(mockServer.URL+"/v1/", "test-api-key")
+
+	message, err := o.Chat(context.Background(), "test-model", []llm.Message{
+		{Role: llm.RoleSystem, Content: "Some JIRA data"},
+		{Role: llm.RoleUser, Content: "Who is assigned?"},
+	}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, llm.Message{Role: llm.RoleAssistant, Content: "Jane Doe."}, message)
+}
+
+func TestChat_Stream(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Authorization"), "no API key should be sent")
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\",\"content\":\"Jane\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\" Doe.\"}}]}\n\n")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer mockServer.Close()
+
+	o := New(mockServer.URL, "")
+
+	var message llm.Message
+	var err error
+	printed := captureStdout(t, func() {
+		message, err = o.Chat(context.Background(), "test-model", []llm.Message{{Role: llm.RoleUser, Content: "Who is assigned?"}}, true)
+	})
+
+	// The streamed message is both printed and returned, to be kept in the chat history
+	assert.NoError(t, err)
+	assert.Equal(t, "Jane Doe.", printed)
+	assert.Equal(t, llm.Message{Role: llm.RoleAssistant, Content: "Jane Doe."}, message)
+}
+
+func TestChat_Errors(t *testing.T) {
+	// The model selects the error returned by the server
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&reqBody)
+		assert.NoError(t, err, "Expected to decode request body without error")
+
+		switch reqBody["model"] {
+		case "unauthorized":
+			w.WriteHeader(http.StatusUnauthorized)
+		case "missing":
+			fmt.Fprint(w, `{"error":{"message":"model not found"}}`)
+		case "empty":
+			fmt.Fprint(w, `{"choices":[]}`)
+		case "long":
+			fmt.Fprint(w, "data: {\"error\":{\"message\":\"context length exceeded\"}}\n\n")
+		case "truncated":
+			// The connection drops before the completion finishes
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\",\"content\":\"Jane\"}}]}\n\n")
+		}
+	}))
+	defer mockServer.Close()
+
+	tests := []struct {
+		model  string
+		stream bool
+		err    string
+	}{
+		{model: "unauthorized", err: "failed to prompt unauthorized: 401 Unauthorized"},
+		{model: "missing", err: "failed to generate response: model not found"},
+		{model: "empty", err: `the "choices" field is missing or empty in the returned JSON: {"choices":[]}`},
+		{model: "long", stream: true, err: "failed to generate response: context length exceeded"},
+		{model: "truncated", stream: true, err: "failed to decode streamed response: unexpected EOF"},
+	}
+
+	o := New(mockServer.URL, "")
+	for _, test := range tests {
+		t.Run(test.model, func(t *testing.T) {
+			_, err := o.Chat(context.Background(), test.model, []llm.Message{{Role: llm.RoleUser, Content: "Hello"}}, test.stream)
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&reqBody)
+		assert.NoError(t, err, "Expected to decode request body without error")
+
+		switch r.URL.Path {
+		case "/v1/chat/completions":
+			// The text prompt and the Jira response of a prompt are joined by a newline
+			content := reqBody["messages"].([]interface{})[0].(map[string]interface{})["content"]
+			assert.Contains(t, []interface{}{"Rendered prompt", "Rendered\nprompt"}, content)
+			fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Chat output"}}]}`)
+		case "/v1/completions":
+			assert.Equal(t, "Raw prompt", reqBody["prompt"])
+			fmt.Fprint(w, `{"choices":[{"text":"Raw output"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mockServer.Close()
+
+	o := New(mockServer.URL, "")
+
+	// A prompt that is not raw goes through the chat template of the model
+	res, err := o.Generate(context.Background(), "test-model", "Rendered prompt", false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "Chat output", res)
+
+	res, err = o.Generate(context.Background(), "test-model", "Raw prompt", false, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "Raw output", res)
+
+	res, err = llm.Prompt(context.Background(), o, "test-model", "Rendered", "prompt", false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "Chat output", res)
+}
+
+func TestEmbed(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+
+		var reqBody map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&reqBody)
+		assert.NoError(t, err, "Expected to decode request body without error")
+		assert.Equal(t, []interface{}{"PROJ-1", "PROJ-2"}, reqBody["input"], "inputs should be sent in order")
+
+		// The embeddings are returned out of order
+		fmt.Fprint(w, `{"data":[{"index":1,"embedding":[0.3,0.4]},{"index":0,"embedding":[0.1,0.2]}]}`)
+	}))
+	defer mockServer.Close()
+
+	o := New(mockServer.URL, "")
+
+	embeddings, err := o.Embed(context.Background(), "embed-model", []string{"PROJ-1", "PROJ-2"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]float64{{0.1, 0.2}, {0.3, 0.4}}, embeddings)
+}
+
+func TestChat_Options(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&reqBody)
+		assert.NoError(t, err, "Expected to decode request body without error")
+
+		assert.Equal(t, 0.2, reqBody["temperature"])
+		assert.Equal(t, 0.9, reqBody["top_p"])
+		assert.Equal(t, []interface{}{"\n\n"}, reqBody["stop"])
+		// Unset options are not sent, nor is the context window which is set by the server
+		assert.NotContains(t, reqBody, "seed")
+		assert.NotContains(t, reqBody, "num_ctx")
+
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Jane Doe."}}]}`)
+	}))
+	defer mockServer.Close()
+
+	temperature, topP := 0.2, 0.9
+	o := New(mockServer.URL, "").WithOptions(llm.Options{Temperature: &temperature, NumCtx: 8192, TopP: &topP, Stop: []string{"\n\n"}})
+
+	_, err := o.Chat(context.Background(), "test-model", []llm.Message{{Role: llm.RoleUser, Content: "Who is assigned?"}}, false)
+	assert.NoError(t, err)
+}
+
+func TestChat_Format(t *testing.T) {
+	var requests []map[string]interface{}
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&reqBody)
+		assert.NoError(t, err, "Expected to decode request body without error")
+		requests = append(requests, reqBody)
+
+		fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"{\"key\":\"PROJ-1\"}"}}]}`)
+	}))
+	defer mockServer.Close()
+
+	messages := []llm.Message{{Role: llm.RoleUser, Content: "Which issue?"}}
+	o := New(mockServer.URL, "").WithFormat(json.RawMessage(`"json"`))
+	_, err := o.Chat(context.Background(), "test-model", messages, false)
+	assert.NoError(t, err)
+
+	_, err = o.WithFormat(json.RawMessage(`{"type":"object"}`)).Chat(context.Background(), "test-model", messages, false)
+	assert.NoError(t, err)
+
+	_, err = o.WithFormat(nil).Chat(context.Background(), "test-model", messages, false)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{"type": "json_object"}, requests[0]["response_format"])
+	assert.Equal(t, map[string]interface{}{
+		"type":        "json_schema",
+		"json_schema": map[string]interface{}{"name": "response", "schema": map[string]interface{}{"type": "object"}},
+	}, requests[1]["response_format"])
+	assert.NotContains(t, requests[2], "response_format", "Unconstrained responses should send no response format")
+}
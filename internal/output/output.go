@@ -0,0 +1,318 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jhandguy/jira-prompt/internal/jira"
+	"gopkg.in/yaml.v3"
+)
+
+// Format is how Jira issues are written.
+type Format string
+
+const (
+	// JSON writes the search response as compact JSON.
+	JSON Format = "json"
+	// Pretty writes the search response as indented JSON.
+	Pretty Format = "pretty"
+	// Table writes the columns of the issues as an aligned text table.
+	Table Format = "table"
+	// CSV writes the columns of the issues as CSV with a header.
+	CSV Format = "csv"
+	// Markdown writes the columns of the issues as a Markdown table.
+	Markdown Format = "markdown"
+	// YAML writes the search response as YAML.
+	YAML Format = "yaml"
+	// JSONL writes each issue as compact JSON on its own line.
+	JSONL Format = "jsonl"
+)
+
+// DefaultColumns are the columns of the table, CSV and Markdown formats if none are given.
+var DefaultColumns = []string{"key", "summary", "status", "assignee"}
+
+// ParseFormat parses a format name.
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(name); format {
+	case JSON, Pretty, Table, CSV, Markdown, YAML, JSONL:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown output %q, expected one of %s, %s, %s, %s, %s, %s or %s", name, JSON, Pretty, Table, CSV, Markdown, YAML, JSONL)
+	}
+}
+
+// ParseIssueFormat parses the name of a format of an issue, one of json, pretty or yaml.
+func ParseIssueFormat(name string) (Format, error) {
+	switch format := Format(name); format {
+	case JSON, Pretty, YAML:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown output %q for an issue, expected one of %s, %s or %s", name, JSON, Pretty, YAML)
+	}
+}
+
+// ParseColumns parses comma separated columns, the default ones if empty.
+func ParseColumns(columns string) []string {
+	var parsed []string
+	for _, column := range strings.Split(columns, ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			parsed = append(parsed, column)
+		}
+	}
+
+	if len(parsed) == 0 {
+		return DefaultColumns
+	}
+	return parsed
+}
+
+// Write writes the search response in the format, with the fields of the projection for the JSON, YAML and JSON Lines formats,
+// or with the columns for the table, CSV and Markdown formats.
+func Write(w io.Writer, format Format, res *jira.SearchResponse, projection jira.Projection, columns []string) error {
+	switch format {
+	case JSON, Pretty, YAML:
+		return writeResponse(w, format, res, projection)
+	case JSONL:
+		return writeLines(w, res.Issues, projection)
+	case Table, CSV, Markdown:
+		rows, err := Rows(res.Issues, columns)
+		if err != nil {
+			return err
+		}
+		return writeRows(w, format, columns, rows)
+	default:
+		return fmt.Errorf("unknown output %q", format)
+	}
+}
+
+// WriteIssue writes an issue, such as an issue tree, in the JSON, pretty JSON or YAML format with the fields of the projection.
+func WriteIssue(w io.Writer, format Format, issue *jira.Issue, projection jira.Projection) error {
+	switch format {
+	case JSON, Pretty, YAML:
+		return writeResponse(w, format, issue, projection)
+	default:
+		_, err := ParseIssueFormat(string(format))
+		return err
+	}
+}
+
+// SprintColumns are the columns of the sprints in the table, CSV and Markdown formats.
+var SprintColumns = []string{"id", "name", "state", "start", "end", "goal"}
+
+// WriteSprints writes the sprints in the format.
+func WriteSprints(w io.Writer, format Format, sprints []jira.Sprint) error {
+	switch format {
+	case JSON, Pretty, YAML:
+		return writeResponse(w, format, sprints, jira.Projection{})
+	case JSONL:
+		for _, sprint := range sprints {
+			data, err := json.Marshal(sprint)
+			if err != nil {
+				return fmt.Errorf("failed to marshal Jira sprint: %w", err)
+			}
+			if _, err = fmt.Fprintln(w, string(data)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		rows := make([][]string, len(sprints))
+		for i, sprint := range sprints {
+			rows[i] = []string{strconv.Itoa(sprint.ID), sprint.Name, sprint.State, sprint.StartDate, sprint.EndDate, sprint.Goal}
+		}
+		return writeRows(w, format, SprintColumns, rows)
+	}
+}
+
+// WriteSprintReport writes a sprint with its issues in the JSON, pretty JSON or YAML format,
+// with the fields of the projection for the issues.
+func WriteSprintReport(w io.Writer, format Format, report *jira.SprintReport, projection jira.Projection) error {
+	if _, err := ParseIssueFormat(string(format)); err != nil {
+		return err
+	}
+	return writeResponse(w, format, report, projection)
+}
+
+func writeResponse(w io.Writer, format Format, v any, projection jira.Projection) error {
+	data, err := projection.Encode(v)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case Pretty:
+		var indented bytes.Buffer
+		if err = json.Indent(&indented, []byte(data), "", "  "); err != nil {
+			return fmt.Errorf("failed to indent Jira issues: %w", err)
+		}
+		data = indented.String() + "\n"
+	case YAML:
+		var v any
+		if err = json.Unmarshal([]byte(data), &v); err != nil {
+			return fmt.Errorf("failed to unmarshal Jira issues: %w", err)
+		}
+
+		var out bytes.Buffer
+		encoder := yaml.NewEncoder(&out)
+		encoder.SetIndent(2)
+		if err = encoder.Encode(v); err != nil {
+			return fmt.Errorf("failed to marshal Jira issues to YAML: %w", err)
+		}
+		data = out.String()
+	}
+
+	_, err = io.WriteString(w, data)
+	return err
+}
+
+func writeLines(w io.Writer, issues []jira.Issue, projection jira.Projection) error {
+	for _, issue := range issues {
+		data, err := projection.Encode(issue)
+		if err != nil {
+			return err
+		}
+
+		if _, err = fmt.Fprintln(w, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeRows(w io.Writer, format Format, columns []string, rows [][]string) error {
+	switch format {
+	case CSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(columns); err != nil {
+			return err
+		}
+		return writer.WriteAll(rows)
+	case Markdown:
+		escape := strings.NewReplacer("|", "\\|", "\r\n", "<br>", "\n", "<br>")
+		if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(columns, " | ")); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "|%s\n", strings.Repeat(" --- |", len(columns))); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			for i := range row {
+				row[i] = escape.Replace(row[i])
+			}
+			if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(row, " | ")); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		header := make([]string, len(columns))
+		for i, column := range columns {
+			header[i] = strings.ToUpper(column)
+		}
+
+		flatten := strings.NewReplacer("\r\n", " ", "\n", " ", "\t", " ")
+		writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		if _, err := fmt.Fprintln(writer, strings.Join(header, "\t")); err != nil {
+			return err
+		}
+		for _, row := range rows {
+			for i := range row {
+				row[i] = flatten.Replace(row[i])
+			}
+			if _, err := fmt.Fprintln(writer, strings.Join(row, "\t")); err != nil {
+				return err
+			}
+		}
+		return writer.Flush()
+	}
+}
+
+// Rows returns the values of the columns of each issue.
+//
+// A column is a top-level issue field such as key, or else a field of the issue fields such as summary,
+// or a path to a nested field such as status.statusCategory.name. Objects are shown by their name,
+// and lists are joined with commas.
+func Rows(issues []jira.Issue, columns []string) ([][]string, error) {
+	rows := make([][]string, 0, len(issues))
+	for _, issue := range issues {
+		data, err := json.Marshal(issue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal Jira issue: %w", err)
+		}
+
+		var fields map[string]any
+		if err = json.Unmarshal(data, &fields); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal Jira issue: %w", err)
+		}
+
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = display(lookup(fields, column))
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// aliases are the column names of issue fields whose name differs.
+var aliases = map[string]string{
+	"type": "issuetype",
+}
+
+func lookup(issue map[string]any, column string) any {
+	path := strings.Split(column, ".")
+	if alias, ok := aliases[path[0]]; ok {
+		path[0] = alias
+	}
+
+	var v any = issue
+	if _, ok := issue[path[0]]; !ok {
+		v = issue["fields"]
+	}
+
+	for _, key := range path {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+// nameKeys are the keys naming an object, in order of preference.
+var nameKeys = []string{"displayName", "name", "value", "key"}
+
+func display(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []any:
+		values := make([]string, len(v))
+		for i, item := range v {
+			values[i] = display(item)
+		}
+		return strings.Join(values, ", ")
+	case map[string]any:
+		for _, key := range nameKeys {
+			if name, ok := v[key]; ok {
+				return display(name)
+			}
+		}
+	}
+
+	data, _ := json.Marshal(v)
+	return string(data)
+}
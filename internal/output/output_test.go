@@ -0,0 +1,181 @@
+package output
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jhandguy/jira-prompt/internal/jira"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSearchResponse(t *testing.T) *jira.SearchResponse {
+	var res jira.SearchResponse
+	err := json.Unmarshal([]byte(`{
+		"issues": [
+			{
+				"id": "10001",
+				"key": "PROJ-1",
+				"fields": {
+					"summary": "Fix the | pipe",
+					"status": {"name": "In Progress", "statusCategory": {"key": "indeterminate", "name": "In Progress"}},
+					"assignee": {"accountId": "1", "displayName": "Jane Doe"},
+					"labels": ["backend", "urgent"],
+					"description": "First line\nSecond line",
+					"customfield_10016": 5
+				}
+			},
+			{
+				"id": "10002",
+				"key": "PROJ-2",
+				"fields": {
+					"summary": "Write, the docs",
+					"status": {"name": "To Do"},
+					"issuetype": {"name": "Task"}
+				}
+			}
+		]
+	}`), &res)
+	assert.NoError(t, err)
+	return &res
+}
+
+func TestWrite(t *testing.T) {
+	columns := []string{"key", "summary", "status", "assignee"}
+
+	testCases := []struct {
+		format   Format
+		expected string
+	}{
+		{
+			format:   JSON,
+			expected: `{"issues":[{"fields":{"assignee":{"accountId":"1","displayName":"Jane Doe"},"customfield_10016":5,"description":"First line\nSecond line","labels":["backend","urgent"],"status":{"name":"In Progress","statusCategory":{"key":"indeterminate","name":"In Progress"}},"summary":"Fix the | pipe"},"key":"PROJ-1"},{"fields":{"issuetype":{"name":"Task"},"status":{"name":"To Do"},"summary":"Write, the docs"},"key":"PROJ-2"}]}`,
+		},
+		{
+			format: Table,
+			expected: `KEY     SUMMARY          STATUS       ASSIGNEE
+PROJ-1  Fix the | pipe   In Progress  Jane Doe
+PROJ-2  Write, the docs  To Do        
+`,
+		},
+		{
+			format: CSV,
+			expected: `key,summary,status,assignee
+PROJ-1,Fix the | pipe,In Progress,Jane Doe
+PROJ-2,"Write, the docs",To Do,
+`,
+		},
+		{
+			format: Markdown,
+			expected: `| key | summary | status | assignee |
+| --- | --- | --- | --- |
+| PROJ-1 | Fix the \| pipe | In Progress | Jane Doe |
+| PROJ-2 | Write, the docs | To Do |  |
+`,
+		},
+		{
+			format: JSONL,
+			expected: `{"fields":{"assignee":{"accountId":"1","displayName":"Jane Doe"},"customfield_10016":5,"description":"First line\nSecond line","labels":["backend","urgent"],"status":{"name":"In Progress","statusCategory":{"key":"indeterminate","name":"In Progress"}},"summary":"Fix the | pipe"},"key":"PROJ-1"}
+{"fields":{"issuetype":{"name":"Task"},"status":{"name":"To Do"},"summary":"Write, the docs"},"key":"PROJ-2"}
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.format), func(t *testing.T) {
+			projection, err := jira.ParseProjection("", "id,self,expand")
+			assert.NoError(t, err)
+
+			var sb strings.Builder
+			err = Write(&sb, tc.format, newSearchResponse(t), projection, columns)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, sb.String())
+		})
+	}
+}
+
+func TestWrite_PrettyAndYAML(t *testing.T) {
+	res := &jira.SearchResponse{Issues: []jira.Issue{{Key: "PROJ-1", Fields: jira.Fields{Summary: "Summary"}}}}
+
+	var sb strings.Builder
+	assert.NoError(t, Write(&sb, Pretty, res, jira.Projection{}, nil))
+	assert.Equal(t, `{
+  "issues": [
+    {
+      "fields": {
+        "summary": "Summary"
+      },
+      "key": "PROJ-1"
+    }
+  ]
+}
+`, sb.String())
+
+	sb.Reset()
+	assert.NoError(t, Write(&sb, YAML, res, jira.Projection{}, nil))
+	assert.Equal(t, `issues:
+  - fields:
+      summary: Summary
+    key: PROJ-1
+`, sb.String())
+}
+
+func TestRows(t *testing.T) {
+	rows, err := Rows(newSearchResponse(t).Issues, []string{"id", "type", "labels", "status.statusCategory.key", "customfield_10016", "description", "unknown"})
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"10001", "", "backend, urgent", "indeterminate", "5", "First line\nSecond line", ""},
+		{"10002", "Task", "", "", "", "", ""},
+	}, rows)
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("csv")
+	assert.NoError(t, err)
+	assert.Equal(t, CSV, format)
+
+	_, err = ParseFormat("xml")
+	assert.EqualError(t, err, `unknown output "xml", expected one of json, pretty, table, csv, markdown, yaml or jsonl`)
+}
+
+func TestParseColumns(t *testing.T) {
+	assert.Equal(t, []string{"key", "labels"}, ParseColumns(" key, ,labels "))
+	assert.Equal(t, DefaultColumns, ParseColumns(""))
+}
+
+func TestWriteIssue(t *testing.T) {
+	issue := &jira.Issue{
+		ID:  "10001",
+		Key: "PROJ-1",
+		Fields: jira.Fields{
+			Summary:  "Epic",
+			Subtasks: []jira.Issue{{ID: "10002", Key: "PROJ-2", Fields: jira.Fields{Summary: "Subtask"}}},
+		},
+	}
+
+	projection, err := jira.ParseProjection("", "id")
+	assert.NoError(t, err)
+
+	var sb strings.Builder
+	assert.NoError(t, WriteIssue(&sb, YAML, issue, projection))
+	assert.Equal(t, `fields:
+  subtasks:
+    - fields:
+        summary: Subtask
+      key: PROJ-2
+  summary: Epic
+key: PROJ-1
+`, sb.String())
+
+	assert.EqualError(t, WriteIssue(&sb, Table, issue, projection), `unknown output "table" for an issue, expected one of json, pretty or yaml`)
+}
+
+func TestWriteSprints(t *testing.T) {
+	sprints := []jira.Sprint{
+		{ID: 11, Name: "Sprint 11", State: jira.SprintActive, StartDate: "2024-01-15", EndDate: "2024-01-29", Goal: "Ship the search"},
+	}
+
+	var sb strings.Builder
+	assert.NoError(t, WriteSprints(&sb, CSV, sprints))
+	assert.Equal(t, "id,name,state,start,end,goal\n11,Sprint 11,active,2024-01-15,2024-01-29,Ship the search\n", sb.String())
+}
@@ -0,0 +1,226 @@
+package recipe
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/jhandguy/jira-prompt/internal/jira"
+	"github.com/jhandguy/jira-prompt/internal/render"
+	"gopkg.in/yaml.v3"
+)
+
+// bundled are the recipes shipped with jp.
+//
+//go:embed recipes/*.yaml
+var bundled embed.FS
+
+// Recipe is a report on Jira issues: which issues are searched, which of their details are fetched,
+// and how the model is prompted about them.
+type Recipe struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Vars are the variables of the recipe, available to its JQL, system and text prompts as {{.name}}.
+	Vars []Var `yaml:"vars"`
+	// JQL is the JQL query of the issues.
+	JQL string `yaml:"jql"`
+	// Fields are the fields of the issues (all of them if empty).
+	Fields []string `yaml:"fields"`
+	// With are the details fetched for each issue: comments, changelog or worklogs.
+	With []string `yaml:"with"`
+	// System is the system prompt, if any.
+	System string `yaml:"system"`
+	// Prompt is the text prompt.
+	Prompt string `yaml:"prompt"`
+	// Template is the prompt template the issues are rendered with, or else they are given as JSON.
+	Template string `yaml:"template"`
+}
+
+// Var is a variable of a recipe, set with --var name=value.
+type Var struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Default     string `yaml:"default"`
+	Required    bool   `yaml:"required"`
+}
+
+// Parse parses a YAML recipe, named after its file unless it sets its own name.
+func Parse(file string, data []byte) (Recipe, error) {
+	var recipe Recipe
+	if err := yaml.Unmarshal(data, &recipe); err != nil {
+		return Recipe{}, fmt.Errorf("failed to unmarshal recipe %s: %w", file, err)
+	}
+
+	if recipe.Name == "" {
+		recipe.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+
+	if recipe.JQL == "" || recipe.Prompt == "" {
+		return Recipe{}, fmt.Errorf("recipe %s requires a jql and a prompt", recipe.Name)
+	}
+
+	if _, err := recipe.Details(jira.DefaultConcurrency); err != nil {
+		return Recipe{}, fmt.Errorf("recipe %s: %w", recipe.Name, err)
+	}
+	return recipe, nil
+}
+
+// Load returns the bundled recipes along with the custom recipes of dir, sorted by name,
+// a custom recipe replacing the bundled one of the same name. A missing dir holds no recipe.
+func Load(dir string) ([]Recipe, error) {
+	recipes := make(map[string]Recipe)
+
+	files, err := fs.Glob(bundled, "recipes/*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bundled recipes: %w", err)
+	}
+	for _, file := range files {
+		data, err := bundled.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bundled recipe %s: %w", file, err)
+		}
+
+		recipe, err := Parse(file, data)
+		if err != nil {
+			return nil, err
+		}
+		recipes[recipe.Name] = recipe
+	}
+
+	if dir != "" {
+		var custom []string
+		for _, pattern := range []string{"*.yaml", "*.yml"} {
+			matches, err := filepath.Glob(filepath.Join(dir, pattern))
+			if err != nil {
+				return nil, fmt.Errorf("failed to list recipes: %w", err)
+			}
+			custom = append(custom, matches...)
+		}
+
+		for _, file := range custom {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read recipe %s: %w", file, err)
+			}
+
+			recipe, err := Parse(file, data)
+			if err != nil {
+				return nil, err
+			}
+			recipes[recipe.Name] = recipe
+		}
+	}
+
+	sorted := make([]Recipe, 0, len(recipes))
+	for _, recipe := range recipes {
+		sorted = append(sorted, recipe)
+	}
+	slices.SortFunc(sorted, func(a, b Recipe) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return sorted, nil
+}
+
+// Find returns the recipe with the given name.
+func Find(recipes []Recipe, name string) (*Recipe, error) {
+	names := make([]string, 0, len(recipes))
+	for _, recipe := range recipes {
+		if recipe.Name == name {
+			return &recipe, nil
+		}
+		names = append(names, recipe.Name)
+	}
+	return nil, fmt.Errorf("unknown recipe %q, expected one of %s", name, strings.Join(names, ", "))
+}
+
+// Resolve returns the recipe with its variables replaced in its JQL, system and text prompts,
+// a variable missing from values taking its default.
+func (r Recipe) Resolve(values map[string]string) (Recipe, error) {
+	vars := make(map[string]string, len(r.Vars))
+	for _, v := range r.Vars {
+		value, ok := values[v.Name]
+		if !ok || value == "" {
+			value = v.Default
+		}
+		if value == "" && v.Required {
+			return Recipe{}, fmt.Errorf("recipe %s requires the variable %s (%s), set it with --var %s=<value>", r.Name, v.Name, v.Description, v.Name)
+		}
+		vars[v.Name] = value
+	}
+
+	for name := range values {
+		if _, ok := vars[name]; !ok {
+			return Recipe{}, fmt.Errorf("unknown variable %q of recipe %s", name, r.Name)
+		}
+	}
+
+	var err error
+	if r.JQL, err = r.execute("jql", r.JQL, vars); err != nil {
+		return Recipe{}, err
+	}
+	if r.System, err = r.execute("system", r.System, vars); err != nil {
+		return Recipe{}, err
+	}
+	if r.Prompt, err = r.execute("prompt", r.Prompt, vars); err != nil {
+		return Recipe{}, err
+	}
+	return r, nil
+}
+
+// execute replaces the variables in a text of the recipe, with quote quoting a JQL value.
+func (r Recipe) execute(name, text string, vars map[string]string) (string, error) {
+	tmpl, err := template.New(r.Name + " " + name).
+		Funcs(template.FuncMap{"quote": jira.Quote}).
+		Option("missingkey=error").
+		Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s of recipe %s: %w", name, r.Name, err)
+	}
+
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("failed to render %s of recipe %s: %w", name, r.Name, err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// SearchRequest returns the Jira search request of the recipe, up to maxIssues issues (0 for no limit).
+func (r Recipe) SearchRequest(maxIssues int) jira.SearchRequest {
+	return jira.SearchRequest{
+		JQL:       r.JQL,
+		Fields:    r.Fields,
+		MaxIssues: maxIssues,
+	}
+}
+
+// Details returns the details fetched for each issue of the recipe.
+func (r Recipe) Details(concurrency int) (jira.Details, error) {
+	details := jira.Details{Concurrency: concurrency}
+	for _, with := range r.With {
+		switch with {
+		case "comments":
+			details.Comments = true
+		case "changelog":
+			details.Changelog = true
+		case "worklogs":
+			details.Worklogs = true
+		default:
+			return jira.Details{}, fmt.Errorf("unknown detail %q, expected one of comments, changelog or worklogs", with)
+		}
+	}
+	return details, nil
+}
+
+// PromptTemplate returns the prompt template of the recipe, nil if the issues are given as JSON.
+func (r Recipe) PromptTemplate() (*render.Template, error) {
+	if r.Template == "" {
+		return nil, nil
+	}
+	return render.Parse(r.Name, r.Template)
+}
@@ -0,0 +1,123 @@
+package recipe
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jhandguy/jira-prompt/internal/jira"
+	"github.com/jhandguy/jira-prompt/internal/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad_Bundled(t *testing.T) {
+	recipes, err := Load(filepath.Join(t.TempDir(), "missing"))
+	assert.NoError(t, err)
+
+	var names []string
+	for _, recipe := range recipes {
+		names = append(names, recipe.Name)
+	}
+	assert.Equal(t, []string{"release-notes", "risk-scan", "sprint-review", "standup", "workload"}, names)
+
+	issues := []jira.Issue{
+		{
+			Key: "PROJ-1",
+			Fields: jira.Fields{
+				Summary:    "Search issues",
+				Status:     &jira.Status{Name: "In Progress"},
+				Updated:    "2024-01-31T09:30:00.000+0100",
+				DueDate:    "2024-02-02",
+				IssueLinks: []jira.IssueLink{{Type: &jira.IssueLinkType{Inward: "is blocked by"}, InwardIssue: &jira.Issue{Key: "PROJ-2"}}},
+				Comment:    &jira.Comments{Comments: []jira.Comment{{Body: jira.Text{Markup: "Waiting on the API"}}}},
+			},
+			Changelog: &jira.Changelog{Histories: []jira.History{{Created: "2024-01-31T09:30:00.000+0100", Items: []jira.ChangeItem{{Field: "status", FromString: "To Do", ToString: "In Progress"}}}}},
+		},
+		{Key: "PROJ-3"},
+	}
+
+	for _, recipe := range recipes {
+		t.Run(recipe.Name, func(t *testing.T) {
+			resolved, err := recipe.Resolve(map[string]string{"project": "PROJ"})
+			if recipe.Name == "release-notes" {
+				assert.EqualError(t, err, "recipe release-notes requires the variable version (fix version of the issues), set it with --var version=<value>")
+				resolved, err = recipe.Resolve(map[string]string{"version": "1.2.0"})
+			}
+			assert.NoError(t, err)
+			assert.NotEmpty(t, resolved.SearchRequest(0).JQL)
+
+			tmpl, err := resolved.PromptTemplate()
+			assert.NoError(t, err)
+
+			res, err := tmpl.Execute(render.Data{Prompt: resolved.Prompt, Issues: issues})
+			assert.NoError(t, err)
+			assert.Contains(t, res, resolved.Prompt)
+			assert.Contains(t, res, "PROJ-3")
+		})
+	}
+}
+
+func TestLoad_Custom(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "standup.yaml"), []byte(`
+jql: assignee = currentUser() AND updated >= -1d
+prompt: What did I do yesterday?
+`), 0o600)
+	assert.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "bugs.yml"), []byte(`
+description: Open bugs
+with: [comments]
+jql: issuetype = Bug AND statusCategory != Done
+prompt: Which bugs should be fixed first?
+`), 0o600)
+	assert.NoError(t, err)
+
+	recipes, err := Load(dir)
+	assert.NoError(t, err)
+	assert.Len(t, recipes, 6)
+
+	standup, err := Find(recipes, "standup")
+	assert.NoError(t, err)
+	assert.Equal(t, "What did I do yesterday?", standup.Prompt)
+	assert.Empty(t, standup.Template)
+
+	bugs, err := Find(recipes, "bugs")
+	assert.NoError(t, err)
+
+	details, err := bugs.Details(2)
+	assert.NoError(t, err)
+	assert.Equal(t, jira.Details{Comments: true, Concurrency: 2}, details)
+
+	_, err = Find(recipes, "retro")
+	assert.EqualError(t, err, `unknown recipe "retro", expected one of bugs, release-notes, risk-scan, sprint-review, standup, workload`)
+}
+
+func TestParse_Errors(t *testing.T) {
+	_, err := Parse("empty.yaml", []byte(`prompt: Hello`))
+	assert.EqualError(t, err, "recipe empty requires a jql and a prompt")
+
+	_, err = Parse("with.yaml", []byte(`{jql: project = PROJ, prompt: Hello, with: [attachments]}`))
+	assert.EqualError(t, err, `recipe with: unknown detail "attachments", expected one of comments, changelog or worklogs`)
+}
+
+func TestResolve(t *testing.T) {
+	recipe := Recipe{
+		Name: "release-notes",
+		Vars: []Var{
+			{Name: "version", Required: true},
+			{Name: "project", Default: "PROJ"},
+		},
+		JQL:    `project = {{quote .project}} AND fixVersion = {{quote .version}}`,
+		Fields: []string{"summary"},
+		Prompt: "Write the release notes of {{.version}}:",
+	}
+
+	resolved, err := recipe.Resolve(map[string]string{"version": `1.2 "beta"`})
+	assert.NoError(t, err)
+	assert.Equal(t, jira.SearchRequest{JQL: `project = "PROJ" AND fixVersion = "1.2 \"beta\""`, Fields: []string{"summary"}, MaxIssues: 10}, resolved.SearchRequest(10))
+	assert.Equal(t, `Write the release notes of 1.2 "beta":`, resolved.Prompt)
+
+	_, err = recipe.Resolve(map[string]string{"version": "1.2", "team": "core"})
+	assert.EqualError(t, err, `unknown variable "team" of recipe release-notes`)
+}
@@ -0,0 +1,130 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jhandguy/jira-prompt/internal/jira"
+)
+
+// jiraTimeLayout is the layout of the timestamps returned by Jira, e.g. 2024-01-31T09:30:00.000+0100.
+const jiraTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+// Data is the data available to prompt templates.
+type Data struct {
+	Prompt string
+	Issues []jira.Issue
+	// Sprint is the sprint of the issues, if prompted about a sprint.
+	Sprint *jira.Sprint
+}
+
+// Template is a prompt template.
+type Template struct {
+	template *template.Template
+}
+
+// Funcs are the helpers available to prompt templates on top of the text/template builtins.
+var Funcs = template.FuncMap{
+	"truncate": truncate,
+	"date":     date,
+	"join":     join,
+	"json":     toJSON,
+}
+
+// Load parses the prompt template at the given path, or else the named template in dir,
+// along with every other template in dir so that they can be reused with {{template "name"}}.
+func Load(name, dir string) (*Template, error) {
+	path := name
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		path = filepath.Join(dir, name+".tmpl")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt template %s: %w", name, err)
+	}
+
+	tmpl := template.New(filepath.Base(path)).Funcs(Funcs)
+
+	shared, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompt templates: %w", err)
+	}
+	for _, file := range shared {
+		if file == path {
+			continue
+		}
+		if tmpl, err = tmpl.ParseFiles(file); err != nil {
+			return nil, fmt.Errorf("failed to parse prompt template %s: %w", file, err)
+		}
+	}
+
+	// The template is parsed last so that its own definitions take precedence
+	if tmpl, err = tmpl.Parse(string(content)); err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template %s: %w", name, err)
+	}
+
+	return &Template{template: tmpl}, nil
+}
+
+// Parse parses a prompt template from its content, e.g. the template of a report recipe.
+func Parse(name, content string) (*Template, error) {
+	tmpl, err := template.New(name).Funcs(Funcs).Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template %s: %w", name, err)
+	}
+	return &Template{template: tmpl}, nil
+}
+
+// Execute renders the prompt template with the given data.
+func (t *Template) Execute(data Data) (string, error) {
+	var buf bytes.Buffer
+	if err := t.template.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// truncate shortens s to at most n characters, ending with an ellipsis if it was shortened.
+func truncate(n int, s string) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	if n < 1 {
+		return ""
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// date formats a Jira timestamp with the given layout, leaving it as is if it cannot be parsed.
+func date(layout, s string) string {
+	for _, l := range []string{jiraTimeLayout, time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(l, s); err == nil {
+			return t.Format(layout)
+		}
+	}
+	return s
+}
+
+// join concatenates the elements of items with sep.
+func join(sep string, items []string) string {
+	return strings.Join(items, sep)
+}
+
+// toJSON marshals v to compact JSON.
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,116 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jhandguy/jira-prompt/internal/jira"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTemplate writes a template file in the given directory and returns its path.
+func writeTemplate(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	err := os.WriteFile(path, []byte(content), 0o600)
+	assert.NoError(t, err, "Expected template file to be written")
+	return path
+}
+
+func TestLoad_Execute(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTemplate(t, dir, "board.tmpl", `{{.Prompt}}
+{{range .Issues}}- {{.Key}} [{{.Fields.Status.Name}}] {{truncate 12 .Fields.Summary}} (updated {{date "2006-01-02" .Fields.Updated}}, labels: {{join ", " .Fields.Labels}})
+{{end}}`)
+
+	tmpl, err := Load(path, t.TempDir())
+	assert.NoError(t, err, "Expected template to be loaded by path")
+
+	res, err := tmpl.Execute(Data{
+		Prompt: "Describe the board:",
+		Issues: []jira.Issue{
+			{
+				Key: "PROJ-1",
+				Fields: jira.Fields{
+					Summary: "A very long issue summary",
+					Status:  &jira.Status{Name: "In Progress"},
+					Updated: "2024-01-31T09:30:00.000+0100",
+					Labels:  []string{"backend", "api"},
+				},
+			},
+		},
+	})
+	assert.NoError(t, err, "Expected template to be rendered")
+	assert.Equal(t, `Describe the board:
+- PROJ-1 [In Progress] A very long… (updated 2024-01-31, labels: backend, api)
+`, res)
+}
+
+func TestLoad_Named(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "issue.tmpl", `{{define "issue"}}{{.Key}}: {{.Fields.Summary}}{{end}}`)
+	writeTemplate(t, dir, "board.tmpl", `{{range .Issues}}{{template "issue" .}}
+{{end}}`)
+
+	// Named templates are looked up in the templates directory and can reuse each other
+	tmpl, err := Load("board", dir)
+	assert.NoError(t, err, "Expected template to be loaded by name")
+
+	res, err := tmpl.Execute(Data{
+		Issues: []jira.Issue{
+			{Key: "PROJ-1", Fields: jira.Fields{Summary: "First"}},
+			{Key: "PROJ-2", Fields: jira.Fields{Summary: "Second"}},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "PROJ-1: First\nPROJ-2: Second\n", res)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	_, err := Load("missing", t.TempDir())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read prompt template missing")
+
+	dir := t.TempDir()
+	writeTemplate(t, dir, "invalid.tmpl", `{{range .Issues}}`)
+	_, err = Load("invalid", dir)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse prompt template")
+
+	dir = t.TempDir()
+	writeTemplate(t, dir, "unknown.tmpl", `{{.Unknown}}`)
+	tmpl, err := Load("unknown", dir)
+	assert.NoError(t, err)
+	_, err = tmpl.Execute(Data{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to render prompt template")
+}
+
+func TestParse(t *testing.T) {
+	tmpl, err := Parse("standup", `{{.Prompt}} {{range .Issues}}{{truncate 4 .Key}}{{end}}`)
+	assert.NoError(t, err, "Expected template to be parsed")
+
+	res, err := tmpl.Execute(Data{Prompt: "Standup:", Issues: []jira.Issue{{Key: "PROJ-1"}}})
+	assert.NoError(t, err)
+	assert.Equal(t, "Standup: PRO…", res)
+
+	_, err = Parse("invalid", `{{range .Issues}}`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse prompt template invalid")
+}
+
+func TestFuncs(t *testing.T) {
+	assert.Equal(t, "short", truncate(10, "short"))
+	assert.Equal(t, "trunc…", truncate(6, "truncated"))
+	assert.Equal(t, "", truncate(0, "truncated"))
+
+	assert.Equal(t, "Jan 31", date("Jan 2", "2024-01-31T09:30:00.000+0100"))
+	assert.Equal(t, "Jan 31", date("Jan 2", "2024-01-31"))
+	assert.Equal(t, "not a date", date("Jan 2", "not a date"), "Expected unparseable dates to be left as is")
+
+	assert.Equal(t, "a|b", join("|", []string{"a", "b"}))
+
+	res, err := toJSON(map[string]string{"key": "PROJ-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"key":"PROJ-1"}`, res)
+}
@@ -0,0 +1,164 @@
+package schema
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/jhandguy/jira-prompt/internal/llm"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"go.uber.org/zap"
+)
+
+// DefaultRetries is the default number of times the model is asked to correct an invalid response.
+const DefaultRetries = 2
+
+// schemaURL is the URL the JSON Schema is compiled at, to which its relative references resolve.
+const schemaURL = "schema.json"
+
+// Schema validates the JSON responses of the model, against a JSON Schema if any.
+type Schema struct {
+	// Raw is the JSON Schema, nil if any JSON is valid.
+	Raw    json.RawMessage
+	schema *jsonschema.Schema
+}
+
+// JSON is the schema of any JSON response.
+var JSON = &Schema{}
+
+// Load loads the JSON Schema file at the given path.
+func Load(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON schema: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses a JSON Schema.
+func Parse(data []byte) (*Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(schemaURL, bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON schema: %w", err)
+	}
+
+	compiled, err := compiler.Compile(schemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile JSON schema: %w", err)
+	}
+
+	var raw bytes.Buffer
+	if err = json.Compact(&raw, data); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON schema: %w", err)
+	}
+	return &Schema{Raw: raw.Bytes(), schema: compiled}, nil
+}
+
+// Format returns the format the responses are constrained to: the JSON Schema, or else "json".
+func (s *Schema) Format() json.RawMessage {
+	if s.Raw != nil {
+		return s.Raw
+	}
+	return json.RawMessage(`"json"`)
+}
+
+// Validate parses the response as JSON, ignoring any Markdown code fence around it,
+// and validates it against the JSON Schema if any, returning it indented.
+func (s *Schema) Validate(response string) (string, error) {
+	response = strings.TrimSpace(response)
+	if fenced, ok := strings.CutPrefix(response, "```"); ok {
+		// The language of the fence, such as json, ends with its first line
+		if _, rest, ok := strings.Cut(fenced, "\n"); ok {
+			fenced = rest
+		}
+		response = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(fenced), "```"))
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(response))
+	decoder.UseNumber()
+
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return "", fmt.Errorf("the response is not valid JSON: %w", err)
+	}
+	if decoder.More() {
+		return "", errors.New("the response is not valid JSON: it holds more than one JSON value")
+	}
+
+	if s.schema != nil {
+		if err := s.schema.Validate(v); err != nil {
+			return "", fmt.Errorf("the response does not match the JSON schema: %s", describe(err))
+		}
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, []byte(response), "", "  "); err != nil {
+		return "", fmt.Errorf("the response is not valid JSON: %w", err)
+	}
+	return indented.String(), nil
+}
+
+// Chat generates the next message of the chat until its content is valid JSON matching the schema,
+// telling the model why an invalid response is rejected up to retries times, and returns it indented.
+func (s *Schema) Chat(ctx context.Context, chat func(ctx context.Context, messages []llm.Message) (llm.Message, error), messages []llm.Message, retries int) (string, error) {
+	messages = append([]llm.Message{}, messages...)
+	for attempt := 0; ; attempt++ {
+		message, err := chat(ctx, messages)
+		if err != nil {
+			return "", err
+		}
+
+		res, err := s.Validate(message.Content)
+		if err == nil {
+			return res, nil
+		}
+
+		if attempt >= retries {
+			return "", fmt.Errorf("failed to get a valid response after %d attempts: %w", attempt+1, err)
+		}
+
+		zap.S().Warnf("🔁 Invalid response, retrying: %v", err)
+		messages = append(messages, message, llm.Message{
+			Role:    llm.RoleUser,
+			Content: s.correction(err),
+		})
+	}
+}
+
+// correction is the prompt correcting an invalid response.
+func (s *Schema) correction(err error) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Your response is invalid, as %v.\n", err)
+	if s.Raw != nil {
+		fmt.Fprintf(&sb, "Respond again with only JSON matching the following JSON schema, without any other text:\n%s", s.Raw)
+	} else {
+		sb.WriteString("Respond again with only valid JSON, without any other text.")
+	}
+	return sb.String()
+}
+
+// describe describes every reason of a validation error, e.g. at '/0/risk': value must be one of "low", "high".
+func describe(err error) string {
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err.Error()
+	}
+
+	var reasons []string
+	var visit func(e *jsonschema.ValidationError)
+	visit = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			reasons = append(reasons, fmt.Sprintf("at '%s': %s", e.InstanceLocation, e.Message))
+			return
+		}
+		for _, cause := range e.Causes {
+			visit(cause)
+		}
+	}
+	visit(validationErr)
+	return strings.Join(reasons, "; ")
+}
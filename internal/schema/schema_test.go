@@ -0,0 +1,118 @@
+package schema
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jhandguy/jira-prompt/internal/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+const risks = `{
+  "type": "array",
+  "items": {
+    "type": "object",
+    "properties": {
+      "key": {"type": "string"},
+      "risk": {"enum": ["low", "high"]},
+      "reason": {"type": "string"}
+    },
+    "required": ["key", "risk", "reason"]
+  }
+}`
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.json")
+	err := os.WriteFile(path, []byte(risks), 0o600)
+	assert.NoError(t, err, "Expected schema file to be written")
+
+	s, err := Load(path)
+	assert.NoError(t, err, "Expected schema to be loaded")
+	assert.JSONEq(t, risks, string(s.Format()))
+	assert.NotContains(t, string(s.Format()), "\n", "Expected schema to be compacted")
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read JSON schema")
+
+	_, err = Parse([]byte(`{"type": "object"`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to parse JSON schema")
+
+	_, err = Parse([]byte(`{"type": "unknown"}`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to compile JSON schema")
+}
+
+func TestValidate(t *testing.T) {
+	s, err := Parse([]byte(risks))
+	assert.NoError(t, err)
+
+	res, err := s.Validate("```json\n[{\"key\":\"PROJ-1\",\"risk\":\"high\",\"reason\":\"Blocked\"}]\n```")
+	assert.NoError(t, err, "Expected fenced JSON matching the schema to be valid")
+	assert.Equal(t, `[
+  {
+    "key": "PROJ-1",
+    "risk": "high",
+    "reason": "Blocked"
+  }
+]`, res)
+
+	_, err = s.Validate(`[{"key":"PROJ-1","risk":"medium"}]`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "the response does not match the JSON schema")
+	assert.Contains(t, err.Error(), "at '/0'")
+	assert.Contains(t, err.Error(), "at '/0/risk'")
+
+	_, err = s.Validate("The riskiest issue is PROJ-1")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "the response is not valid JSON")
+
+	_, err = JSON.Validate(`{"key":"PROJ-1"} {"key":"PROJ-2"}`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "more than one JSON value")
+
+	res, err = JSON.Validate(`{"key":"PROJ-1","points":12345678901234567890}`)
+	assert.NoError(t, err, "Expected any JSON to be valid without a schema")
+	assert.Equal(t, "{\n  \"key\": \"PROJ-1\",\n  \"points\": 12345678901234567890\n}", res)
+	assert.Equal(t, `"json"`, string(JSON.Format()))
+}
+
+func TestChat(t *testing.T) {
+	s, err := Parse([]byte(risks))
+	assert.NoError(t, err)
+
+	var requests [][]llm.Message
+	responses := []string{"PROJ-1 is at risk", `[{"key":"PROJ-1","risk":"medium","reason":"Blocked"}]`, `[{"key":"PROJ-1","risk":"high","reason":"Blocked"}]`}
+	chat := func(_ context.Context, messages []llm.Message) (llm.Message, error) {
+		requests = append(requests, messages)
+		return llm.Message{Role: llm.RoleAssistant, Content: responses[len(requests)-1]}, nil
+	}
+
+	messages := []llm.Message{{Role: llm.RoleUser, Content: "Which issues are at risk?"}}
+	res, err := s.Chat(context.Background(), chat, messages, 2)
+	assert.NoError(t, err, "Expected the model to correct its response")
+	assert.JSONEq(t, responses[2], res)
+
+	// Each retry tells the model why its previous response was rejected
+	assert.Len(t, requests, 3)
+	assert.Len(t, requests[2], 5)
+	assert.Equal(t, responses[1], requests[2][3].Content)
+	assert.Equal(t, llm.RoleUser, requests[2][4].Role)
+	assert.Contains(t, requests[2][4].Content, "at '/0/risk'")
+	assert.Contains(t, requests[2][4].Content, string(s.Raw))
+	assert.Len(t, messages, 1, "Expected the messages of the caller to be left as is")
+
+	requests = nil
+	_, err = s.Chat(context.Background(), chat, messages, 1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get a valid response after 2 attempts")
+
+	_, err = s.Chat(context.Background(), func(context.Context, []llm.Message) (llm.Message, error) {
+		return llm.Message{}, errors.New("connection refused")
+	}, messages, 2)
+	assert.EqualError(t, err, "connection refused")
+}
@@ -0,0 +1,255 @@
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jhandguy/jira-prompt/internal/jira"
+	"github.com/jhandguy/jira-prompt/internal/llm"
+	"go.uber.org/zap"
+)
+
+// Strategy is how Jira issues are given to the model.
+type Strategy string
+
+const (
+	// Stuff gives every issue to the model at once, whether it fits in the context window or not.
+	Stuff Strategy = "stuff"
+	// MapReduce summarizes batches of issues that fit in the context window, then prompts over the summaries.
+	MapReduce Strategy = "map-reduce"
+	// Refine summarizes the first batch of issues, then refines the summary with each following batch.
+	Refine Strategy = "refine"
+)
+
+// responseRatio is the ratio of the context window left for the response of the model.
+const responseRatio = 4
+
+// ParseStrategy parses a strategy name.
+func ParseStrategy(name string) (Strategy, error) {
+	switch strategy := Strategy(name); strategy {
+	case Stuff, MapReduce, Refine:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unknown strategy %q, expected one of %s, %s or %s", name, Stuff, MapReduce, Refine)
+	}
+}
+
+// EstimateTokens estimates the number of tokens of the text, at about 4 characters per token.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// Summarizer turns Jira issues into the context of a prompt that fits in the context window.
+type Summarizer struct {
+	Strategy Strategy
+	// NumCtx is the size of the context window in tokens.
+	NumCtx int
+	// Render renders a batch of issues as text.
+	Render func(issues []jira.Issue) (string, error)
+	// Generate generates the response to an intermediate prompt.
+	Generate func(ctx context.Context, prompt string) (string, error)
+}
+
+// Request returns the request of the text prompt over the context of the issues, following the system prompt if any.
+// A rendered template already holds the text prompt, unlike summaries.
+func (s *Summarizer) Request(ctx context.Context, system, textPrompt string, templated bool, issues []jira.Issue) (llm.Request, error) {
+	res, summarized, err := s.Context(ctx, textPrompt, issues)
+	if err != nil {
+		return llm.Request{}, err
+	}
+
+	return llm.Request{
+		System:    system,
+		Prompt:    textPrompt,
+		Context:   res,
+		Templated: templated && !summarized,
+	}, nil
+}
+
+// Context returns the context given to the model along with the text prompt, and whether it was summarized,
+// in which case it holds the partial summaries for map-reduce or the refined summary for refine.
+func (s *Summarizer) Context(ctx context.Context, textPrompt string, issues []jira.Issue) (string, bool, error) {
+	if s.Strategy == Stuff {
+		return s.stuff(textPrompt, issues)
+	}
+
+	// The instructions of the intermediate prompts take up some of the context window too
+	budget := s.NumCtx - s.NumCtx/responseRatio - EstimateTokens(textPrompt+mapPrompt)
+	if s.Strategy == Refine {
+		// And so does the summary being refined
+		budget = s.NumCtx - 2*(s.NumCtx/responseRatio) - EstimateTokens(textPrompt+refinePrompt)
+	}
+	if budget <= 0 {
+		return "", false, fmt.Errorf("context window of %d tokens is too small for the prompt", s.NumCtx)
+	}
+
+	batches, err := Batch(issues, budget, s.Render)
+	if err != nil {
+		return "", false, err
+	}
+
+	if len(batches) < 2 {
+		return s.stuff(textPrompt, issues)
+	}
+
+	zap.S().Infof("🧩 Split %d issues into %d batches of about %d tokens", len(issues), len(batches), budget)
+	var data string
+	switch s.Strategy {
+	case MapReduce:
+		data, err = s.mapReduce(ctx, textPrompt, batches)
+	case Refine:
+		data, err = s.refine(ctx, textPrompt, batches)
+	default:
+		err = fmt.Errorf("unknown strategy %q", s.Strategy)
+	}
+	return data, err == nil, err
+}
+
+// stuff renders every issue at once.
+func (s *Summarizer) stuff(textPrompt string, issues []jira.Issue) (string, bool, error) {
+	data, err := s.Render(issues)
+	if err != nil {
+		return "", false, err
+	}
+
+	if tokens := EstimateTokens(textPrompt + data); tokens > s.NumCtx {
+		zap.S().Warnf("⚠️ Prompt of about %d tokens exceeds the context window of %d tokens", tokens, s.NumCtx)
+	}
+	return data, false, nil
+}
+
+const (
+	mapPrompt     = "%s\n\nThe following is part %d of %d of the Jira issues. Summarize the information relevant to the above:\n%s"
+	reducePrompt  = "%s\n\nThe following are the summaries of part %d of %d of the Jira issues. Combine them into a single summary of the information relevant to the above:\n%s"
+	summaryHeader = "Summary of part %d of %d of the Jira issues:\n%s"
+	refinePrompt  = "%s\n\nHere is the summary of the previous Jira issues:\n%s\n\nRefine the summary with the information relevant to the above from part %d of %d of the Jira issues:\n%s"
+)
+
+// mapReduce summarizes each batch, and returns the partial summaries for the final prompt,
+// once reduced to fit in the context window.
+func (s *Summarizer) mapReduce(ctx context.Context, textPrompt string, batches [][]jira.Issue) (string, error) {
+	summaries := make([]string, 0, len(batches))
+	for i, batch := range batches {
+		zap.S().Infof("🗺️ Summarizing batch %d of %d...", i+1, len(batches))
+		data, err := s.Render(batch)
+		if err != nil {
+			return "", err
+		}
+
+		summary, err := s.Generate(ctx, fmt.Sprintf(mapPrompt, textPrompt, i+1, len(batches), data))
+		if err != nil {
+			return "", err
+		}
+		summaries = append(summaries, fmt.Sprintf(summaryHeader, i+1, len(batches), strings.TrimSpace(summary)))
+	}
+	return s.reduce(ctx, textPrompt, summaries)
+}
+
+// reduce combines the summaries fitting in the budget together into a summary, until every summary fits at once,
+// and returns them.
+func (s *Summarizer) reduce(ctx context.Context, textPrompt string, summaries []string) (string, error) {
+	budget := s.NumCtx - s.NumCtx/responseRatio - EstimateTokens(textPrompt+reducePrompt)
+	for {
+		data := strings.Join(summaries, "\n\n")
+		tokens := EstimateTokens(data)
+		if tokens <= budget {
+			return data, nil
+		}
+
+		groups := group(summaries, budget)
+		if len(groups) == len(summaries) {
+			// No summaries fit together, so reducing them further would not make them shorter
+			zap.S().Warnf("⚠️ Summaries of about %d tokens exceed the budget of %d tokens", tokens, budget)
+			return data, nil
+		}
+
+		zap.S().Infof("🧮 Reducing %d summaries into %d...", len(summaries), len(groups))
+		reduced := make([]string, 0, len(groups))
+		for i, g := range groups {
+			summary, err := s.Generate(ctx, fmt.Sprintf(reducePrompt, textPrompt, i+1, len(groups), strings.Join(g, "\n\n")))
+			if err != nil {
+				return "", err
+			}
+			reduced = append(reduced, fmt.Sprintf(summaryHeader, i+1, len(groups), strings.TrimSpace(summary)))
+		}
+		summaries = reduced
+	}
+}
+
+// group splits the summaries into groups whose text fits in the token budget,
+// a summary that does not fit on its own being in a group of its own.
+func group(summaries []string, budget int) [][]string {
+	var groups [][]string
+	var g []string
+	var tokens int
+	for _, summary := range summaries {
+		summaryTokens := EstimateTokens(summary + "\n\n")
+		if len(g) > 0 && tokens+summaryTokens > budget {
+			groups = append(groups, g)
+			g, tokens = nil, 0
+		}
+		g = append(g, summary)
+		tokens += summaryTokens
+	}
+
+	if len(g) > 0 {
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// refine summarizes the first batch, then refines the summary with each following batch, and returns it.
+func (s *Summarizer) refine(ctx context.Context, textPrompt string, batches [][]jira.Issue) (string, error) {
+	var summary string
+	for i, batch := range batches {
+		zap.S().Infof("🔁 Refining summary with batch %d of %d...", i+1, len(batches))
+		data, err := s.Render(batch)
+		if err != nil {
+			return "", err
+		}
+
+		prompt := fmt.Sprintf(mapPrompt, textPrompt, i+1, len(batches), data)
+		if i > 0 {
+			prompt = fmt.Sprintf(refinePrompt, textPrompt, summary, i+1, len(batches), data)
+		}
+
+		if summary, err = s.Generate(ctx, prompt); err != nil {
+			return "", err
+		}
+		summary = strings.TrimSpace(summary)
+	}
+	return summary, nil
+}
+
+// Batch splits the issues into batches whose rendering fits in the token budget,
+// an issue that does not fit on its own being in a batch of its own.
+func Batch(issues []jira.Issue, budget int, render func(issues []jira.Issue) (string, error)) ([][]jira.Issue, error) {
+	var batches [][]jira.Issue
+	var batch []jira.Issue
+	var tokens int
+	for _, issue := range issues {
+		data, err := render([]jira.Issue{issue})
+		if err != nil {
+			return nil, err
+		}
+
+		issueTokens := EstimateTokens(data)
+		if issueTokens > budget {
+			zap.S().Warnf("⚠️ Issue %s of about %d tokens exceeds the budget of %d tokens", issue.Key, issueTokens, budget)
+		}
+
+		if len(batch) > 0 && tokens+issueTokens > budget {
+			batches = append(batches, batch)
+			batch, tokens = nil, 0
+		}
+		batch = append(batch, issue)
+		tokens += issueTokens
+	}
+
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
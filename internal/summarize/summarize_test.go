@@ -0,0 +1,259 @@
+package summarize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jhandguy/jira-prompt/internal/jira"
+	"github.com/jhandguy/jira-prompt/internal/llm"
+	"github.com/stretchr/testify/assert"
+)
+
+// renderKeys renders each issue as its key followed by its summary.
+func renderKeys(issues []jira.Issue) (string, error) {
+	lines := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		lines = append(lines, fmt.Sprintf("%s %s", issue.Key, issue.Fields.Summary))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// newIssues creates n issues with summaries of about 10 tokens.
+func newIssues(n int) []jira.Issue {
+	issues := make([]jira.Issue, 0, n)
+	for i := range n {
+		issues = append(issues, jira.Issue{
+			Key:    fmt.Sprintf("PROJ-%d", i+1),
+			Fields: jira.Fields{Summary: strings.Repeat("x", 30)},
+		})
+	}
+	return issues
+}
+
+func TestParseStrategy(t *testing.T) {
+	for _, name := range []string{"stuff", "map-reduce", "refine"} {
+		strategy, err := ParseStrategy(name)
+		assert.NoError(t, err)
+		assert.Equal(t, Strategy(name), strategy)
+	}
+
+	_, err := ParseStrategy("unknown")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown strategy "unknown"`)
+}
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, 0, EstimateTokens(""))
+	assert.Equal(t, 1, EstimateTokens("abc"))
+	assert.Equal(t, 2, EstimateTokens("abcdefgh"))
+	assert.Equal(t, 1, EstimateTokens("éèà"), "Expected characters rather than bytes to be counted")
+}
+
+func TestBatch(t *testing.T) {
+	// Each rendered issue is about 10 tokens, so 3 of them fit in a budget of 30
+	batches, err := Batch(newIssues(7), 30, renderKeys)
+	assert.NoError(t, err)
+	assert.Len(t, batches, 3, "Expected issues to be split into 3 batches")
+	assert.Len(t, batches[0], 3)
+	assert.Len(t, batches[1], 3)
+	assert.Len(t, batches[2], 1)
+
+	// An issue exceeding the budget gets a batch of its own
+	batches, err = Batch(newIssues(2), 5, renderKeys)
+	assert.NoError(t, err)
+	assert.Len(t, batches, 2)
+
+	// Rendering errors are returned
+	_, err = Batch(newIssues(1), 30, func([]jira.Issue) (string, error) {
+		return "", errors.New("render error")
+	})
+	assert.EqualError(t, err, "render error")
+}
+
+func TestContext(t *testing.T) {
+	testCases := []struct {
+		name               string
+		strategy           Strategy
+		numCtx             int
+		issues             int
+		expectedSummarized bool
+		expectedPrompts    int
+	}{
+		{
+			name:            "stuffExceedingContextWindow",
+			strategy:        Stuff,
+			numCtx:          100,
+			issues:          20,
+			expectedPrompts: 0,
+		},
+		{
+			name:            "mapReduceFittingContextWindow",
+			strategy:        MapReduce,
+			numCtx:          1000,
+			issues:          5,
+			expectedPrompts: 0,
+		},
+		{
+			// About 100 tokens are left per batch, that is 10 issues
+			name:               "mapReduceExceedingContextWindow",
+			strategy:           MapReduce,
+			numCtx:             200,
+			issues:             25,
+			expectedSummarized: true,
+			expectedPrompts:    3,
+		},
+		{
+			// About 150 tokens are left per batch, that is 15 issues
+			name:               "refineExceedingContextWindow",
+			strategy:           Refine,
+			numCtx:             400,
+			issues:             40,
+			expectedSummarized: true,
+			expectedPrompts:    3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var prompts []string
+			s := &Summarizer{
+				Strategy: tc.strategy,
+				NumCtx:   tc.numCtx,
+				Render:   renderKeys,
+				Generate: func(_ context.Context, prompt string) (string, error) {
+					prompts = append(prompts, prompt)
+					return fmt.Sprintf("summary %d", len(prompts)), nil
+				},
+			}
+
+			context, summarized, err := s.Context(context.Background(), "What is the team working on?", newIssues(tc.issues))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedSummarized, summarized)
+			assert.Len(t, prompts, tc.expectedPrompts, "Unexpected number of intermediate prompts")
+
+			for _, prompt := range prompts {
+				assert.True(t, strings.HasPrefix(prompt, "What is the team working on?"), "Expected intermediate prompts to start with the text prompt")
+			}
+
+			switch {
+			case !tc.expectedSummarized:
+				// Every issue is rendered at once
+				assert.Contains(t, context, "PROJ-1 ")
+				assert.Contains(t, context, fmt.Sprintf("PROJ-%d ", tc.issues))
+			case tc.strategy == MapReduce:
+				// Every partial summary is given to the reduce pass
+				assert.Equal(t, "Summary of part 1 of 3 of the Jira issues:\nsummary 1\n\n"+
+					"Summary of part 2 of 3 of the Jira issues:\nsummary 2\n\n"+
+					"Summary of part 3 of 3 of the Jira issues:\nsummary 3", context)
+			case tc.strategy == Refine:
+				// Each summary is refined with the next batch, and the last one is returned
+				assert.Contains(t, prompts[1], "summary 1")
+				assert.Contains(t, prompts[2], "summary 2")
+				assert.Equal(t, "summary 3", context)
+			}
+		})
+	}
+}
+
+func TestContext_Reduce(t *testing.T) {
+	testCases := []struct {
+		name            string
+		summary         int
+		expectedReduces int
+		expected        string
+	}{
+		{
+			// The 3 partial summaries of about 40 tokens exceed the budget of about 100 tokens, but 2 of them fit in it
+			name:            "reduced",
+			summary:         120,
+			expectedReduces: 2,
+			expected: "Summary of part 1 of 2 of the Jira issues:\nreduced 1\n\n" +
+				"Summary of part 2 of 2 of the Jira issues:\nreduced 2",
+		},
+		{
+			// No 2 partial summaries of about 80 tokens fit in the budget together
+			name:            "notReducible",
+			summary:         280,
+			expectedReduces: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var maps, reduces []string
+			s := &Summarizer{
+				Strategy: MapReduce,
+				NumCtx:   200,
+				Render:   renderKeys,
+				Generate: func(_ context.Context, prompt string) (string, error) {
+					if strings.Contains(prompt, "Combine them into a single summary") {
+						reduces = append(reduces, prompt)
+						return fmt.Sprintf("reduced %d", len(reduces)), nil
+					}
+					maps = append(maps, prompt)
+					return strings.Repeat("y", tc.summary), nil
+				},
+			}
+
+			context, summarized, err := s.Context(context.Background(), "What is the team working on?", newIssues(25))
+			assert.NoError(t, err)
+			assert.True(t, summarized)
+			assert.Len(t, maps, 3, "Unexpected number of map prompts")
+			assert.Len(t, reduces, tc.expectedReduces, "Unexpected number of reduce prompts")
+
+			if tc.expectedReduces > 0 {
+				assert.Contains(t, reduces[0], "Summary of part 1 of 3 of the Jira issues:")
+				assert.Contains(t, reduces[0], "Summary of part 2 of 3 of the Jira issues:")
+				assert.Contains(t, reduces[1], "Summary of part 3 of 3 of the Jira issues:")
+				assert.Equal(t, tc.expected, context)
+			} else {
+				// The partial summaries are given as they are, with a warning
+				assert.Contains(t, context, "Summary of part 3 of 3 of the Jira issues:")
+			}
+		})
+	}
+}
+
+func TestContext_Errors(t *testing.T) {
+	// The context window cannot even fit the prompt
+	s := &Summarizer{Strategy: MapReduce, NumCtx: 10, Render: renderKeys}
+	_, _, err := s.Context(context.Background(), "What is the team working on?", newIssues(1))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "context window of 10 tokens is too small")
+
+	// Generation errors are returned
+	s = &Summarizer{
+		Strategy: MapReduce,
+		NumCtx:   200,
+		Render:   renderKeys,
+		Generate: func(context.Context, string) (string, error) {
+			return "", errors.New("generate error")
+		},
+	}
+	_, _, err = s.Context(context.Background(), "What is the team working on?", newIssues(12))
+	assert.EqualError(t, err, "generate error")
+}
+
+func TestRequest(t *testing.T) {
+	s := &Summarizer{
+		Strategy: MapReduce,
+		NumCtx:   200,
+		Render:   renderKeys,
+		Generate: func(context.Context, string) (string, error) {
+			return "summary", nil
+		},
+	}
+
+	// A rendered template holds the text prompt
+	request, err := s.Request(context.Background(), "You are a scrum master.", "What is the team working on?", true, newIssues(2))
+	assert.NoError(t, err)
+	assert.Equal(t, llm.Request{System: "You are a scrum master.", Prompt: "What is the team working on?", Context: "PROJ-1 xxxxxxxxxxxxxxxxxxxxxxxxxxxxxx\nPROJ-2 xxxxxxxxxxxxxxxxxxxxxxxxxxxxxx", Templated: true}, request)
+
+	// Unlike summaries of the rendered template
+	request, err = s.Request(context.Background(), "", "What is the team working on?", true, newIssues(60))
+	assert.NoError(t, err)
+	assert.False(t, request.Templated, "Expected summaries to be preceded by the text prompt")
+}
@@ -0,0 +1,209 @@
+package vector
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jhandguy/jira-prompt/internal/jira"
+)
+
+// DefaultModel is the default embedding model.
+const DefaultModel = "nomic-embed-text"
+
+// Fields are the Jira fields of the indexed issues, the summary, description and update time being always indexed.
+var Fields = []string{"summary", "description", "updated", "status", "assignee", "issuetype", "priority", "labels"}
+
+// requiredFields are the fields an issue is embedded and kept up to date with.
+var requiredFields = []string{"summary", "description", "updated"}
+
+// Index is a vector index of Jira issues, keyed by issue key, whose embeddings are computed with a single model.
+type Index struct {
+	// Model is the model of the embeddings, empty if no issue is indexed yet.
+	Model   string           `json:"model"`
+	Entries map[string]Entry `json:"entries"`
+}
+
+// Entry is an indexed Jira issue.
+type Entry struct {
+	// Updated is the update time of the issue when it was embedded.
+	Updated string     `json:"updated"`
+	Issue   jira.Issue `json:"issue"`
+	Vector  []float64  `json:"vector"`
+}
+
+// Match is an indexed Jira issue relevant to a query, the higher the score the more relevant.
+type Match struct {
+	Issue jira.Issue
+	Score float64
+}
+
+// Load reads the index at the given path, a missing index being empty.
+func Load(path string) (*Index, error) {
+	index := &Index{Entries: make(map[string]Entry)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	if err = json.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal index: %w", err)
+	}
+	if index.Entries == nil {
+		index.Entries = make(map[string]Entry)
+	}
+	return index, nil
+}
+
+// Save writes the index at the given path.
+func (i *Index) Save(path string) error {
+	data, err := json.Marshal(i)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index: %w", err)
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create index directory: %w", err)
+	}
+
+	// The file is written aside and renamed, so that it is never left half written
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write index: %w", err)
+	}
+	return nil
+}
+
+// Reset empties the index if its embeddings were computed with another model.
+func (i *Index) Reset(model string) bool {
+	if i.Model == model {
+		return false
+	}
+
+	reset := len(i.Entries) > 0
+	i.Model = model
+	i.Entries = make(map[string]Entry)
+	return reset
+}
+
+// Stale returns the issues that are not indexed, or were updated since they were indexed.
+func (i *Index) Stale(issues []jira.Issue) []jira.Issue {
+	var stale []jira.Issue
+	for _, issue := range issues {
+		entry, ok := i.Entries[issue.Key]
+		if !ok || entry.Updated != issue.Fields.Updated {
+			stale = append(stale, issue)
+		}
+	}
+	return stale
+}
+
+// Add indexes the issue with its embedding, replacing any previous one.
+func (i *Index) Add(issue jira.Issue, vector []float64) {
+	i.Entries[issue.Key] = Entry{
+		Updated: issue.Fields.Updated,
+		Issue:   issue,
+		Vector:  vector,
+	}
+}
+
+// Prune removes the indexed issues other than the given ones, e.g. once deleted or moved, and returns how many were removed.
+func (i *Index) Prune(issues []jira.Issue) int {
+	keys := make(map[string]bool, len(issues))
+	for _, issue := range issues {
+		keys[issue.Key] = true
+	}
+
+	removed := 0
+	for key := range i.Entries {
+		if !keys[key] {
+			delete(i.Entries, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Search returns the k indexed issues most similar to the embedding of the query, by cosine similarity.
+func (i *Index) Search(vector []float64, k int) []Match {
+	matches := make([]Match, 0, len(i.Entries))
+	for _, entry := range i.Entries {
+		matches = append(matches, Match{Issue: entry.Issue, Score: cosine(vector, entry.Vector)})
+	}
+
+	// Issues are ordered by key for equal scores, so that the results are deterministic
+	slices.SortFunc(matches, func(a, b Match) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), strings.Compare(a.Issue.Key, b.Issue.Key))
+	})
+	return matches[:min(k, len(matches))]
+}
+
+// SearchFields returns the fields to search the issues to index with: the given ones if any or else Fields,
+// along with the required ones.
+func SearchFields(fields []string) []string {
+	if len(fields) == 0 {
+		return slices.Clone(Fields)
+	}
+
+	fields = slices.Clone(fields)
+	for _, field := range requiredFields {
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// Document returns the text an issue is embedded with: its key, summary, description and comments.
+func Document(issue jira.Issue) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %s", issue.Key, issue.Fields.Summary)
+
+	if description := strings.TrimSpace(issue.Fields.Description.Plain()); description != "" {
+		fmt.Fprintf(&sb, "\n\n%s", description)
+	}
+
+	if issue.Fields.Comment != nil && len(issue.Fields.Comment.Comments) > 0 {
+		sb.WriteString("\n\nComments:")
+		for _, comment := range issue.Fields.Comment.Comments {
+			author := "unknown"
+			if comment.Author != nil {
+				author = comment.Author.DisplayName
+			}
+			fmt.Fprintf(&sb, "\n- %s: %s", author, strings.TrimSpace(comment.Body.Plain()))
+		}
+	}
+	return sb.String()
+}
+
+// cosine returns the cosine similarity of two vectors, 0 if they differ in size or either is zero.
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for j := range a {
+		dot += a[j] * b[j]
+		normA += a[j] * a[j]
+		normB += b[j] * b[j]
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
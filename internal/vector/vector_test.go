@@ -0,0 +1,106 @@
+package vector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jhandguy/jira-prompt/internal/jira"
+	"github.com/stretchr/testify/assert"
+)
+
+func newIssue(key, summary, updated string) jira.Issue {
+	return jira.Issue{Key: key, Fields: jira.Fields{Summary: summary, Updated: updated}}
+}
+
+func TestLoad_Save(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jp", "index.json")
+
+	idx, err := Load(path)
+	assert.NoError(t, err, "Expected a missing index to be empty")
+	assert.Empty(t, idx.Entries)
+	assert.Empty(t, idx.Model)
+
+	assert.False(t, idx.Reset("nomic-embed-text"), "Expected an empty index not to be reset")
+	idx.Add(newIssue("PROJ-1", "Login fails", "2024-01-31T09:30:00.000+0100"), []float64{1, 0})
+	err = idx.Save(path)
+	assert.NoError(t, err, "Expected index to be saved")
+
+	loaded, err := Load(path)
+	assert.NoError(t, err, "Expected index to be loaded")
+	assert.Equal(t, idx, loaded)
+
+	assert.True(t, loaded.Reset("mxbai-embed-large"), "Expected an index of another model to be reset")
+	assert.Empty(t, loaded.Entries)
+	assert.Equal(t, "mxbai-embed-large", loaded.Model)
+
+	err = os.WriteFile(path, []byte("{"), 0o600)
+	assert.NoError(t, err)
+	_, err = Load(path)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to unmarshal index")
+}
+
+func TestStale_Prune(t *testing.T) {
+	idx := &Index{Entries: make(map[string]Entry)}
+	idx.Add(newIssue("PROJ-1", "Login fails", "2024-01-31T09:30:00.000+0100"), []float64{1, 0})
+	idx.Add(newIssue("PROJ-2", "Logout fails", "2024-01-31T09:30:00.000+0100"), []float64{0, 1})
+	idx.Add(newIssue("PROJ-3", "Deleted", "2024-01-31T09:30:00.000+0100"), []float64{1, 1})
+
+	issues := []jira.Issue{
+		newIssue("PROJ-1", "Login fails", "2024-01-31T09:30:00.000+0100"),
+		newIssue("PROJ-2", "Logout fails", "2024-02-01T10:00:00.000+0100"),
+		newIssue("PROJ-4", "Signup fails", "2024-02-01T10:00:00.000+0100"),
+	}
+
+	// Only the updated and new issues are embedded again
+	stale := idx.Stale(issues)
+	assert.Equal(t, []jira.Issue{issues[1], issues[2]}, stale)
+
+	assert.Equal(t, 1, idx.Prune(issues))
+	assert.Len(t, idx.Entries, 2)
+	assert.NotContains(t, idx.Entries, "PROJ-3")
+}
+
+func TestSearch(t *testing.T) {
+	idx := &Index{Entries: make(map[string]Entry)}
+	idx.Add(newIssue("PROJ-1", "Login fails", ""), []float64{1, 0})
+	idx.Add(newIssue("PROJ-2", "Logout fails", ""), []float64{0.8, 0.6})
+	idx.Add(newIssue("PROJ-3", "Dark mode", ""), []float64{0, 1})
+	idx.Add(newIssue("PROJ-4", "Malformed", ""), []float64{1})
+
+	matches := idx.Search([]float64{2, 0}, 2)
+	assert.Len(t, matches, 2)
+	assert.Equal(t, "PROJ-1", matches[0].Issue.Key)
+	assert.InDelta(t, 1, matches[0].Score, 1e-9)
+	assert.Equal(t, "PROJ-2", matches[1].Issue.Key)
+	assert.InDelta(t, 0.8, matches[1].Score, 1e-9)
+
+	matches = idx.Search([]float64{1, 0}, 10)
+	assert.Len(t, matches, 4, "Expected at most every issue to match")
+	assert.Equal(t, 0.0, matches[3].Score, "Expected vectors of another size not to match")
+}
+
+func TestSearchFields(t *testing.T) {
+	assert.Equal(t, Fields, SearchFields(nil))
+	assert.Equal(t, []string{"summary", "status", "description", "updated"}, SearchFields([]string{"summary", "status"}))
+}
+
+func TestDocument(t *testing.T) {
+	issue := newIssue("PROJ-1", "Login fails", "")
+	issue.Fields.Description = jira.Text{Markup: "The *login* page returns a 500."}
+	issue.Fields.Comment = &jira.Comments{Comments: []jira.Comment{
+		{Author: &jira.User{DisplayName: "Jane Doe"}, Body: jira.Text{Markup: "Caused by the session store."}},
+		{Body: jira.Text{Markup: "Fixed."}},
+	}}
+
+	assert.Equal(t, `PROJ-1: Login fails
+
+The login page returns a 500.
+
+Comments:
+- Jane Doe: Caused by the session store.
+- unknown: Fixed.`, Document(issue))
+
+	assert.Equal(t, "PROJ-2: Logout fails", Document(newIssue("PROJ-2", "Logout fails", "")))
+}